@@ -0,0 +1,117 @@
+// Package apierror renders API errors as the ErrorResponse of openapi.yml:
+// always JSON, always with a code, and tagged with the request ID.
+package apierror
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"reviewer-service/app/service"
+)
+
+// Code is the error code of a response. Domain codes are the ones defined by
+// the service package; the constants below only add the codes raised by the
+// HTTP layer itself.
+type Code = service.Code
+
+const (
+	CodeTimeout Code = "TIMEOUT"
+	// CodeIdempotencyKeyReused rejects a request whose Idempotency-Key was
+	// first sent with a different request.
+	CodeIdempotencyKeyReused Code = "IDEMPOTENCY_KEY_REUSED"
+	// CodeIdempotencyKeyInUse rejects a retry that arrives while the first
+	// request with the same key is still being handled.
+	CodeIdempotencyKeyInUse Code = "IDEMPOTENCY_KEY_IN_USE"
+	CodeInternal            Code = "INTERNAL"
+)
+
+// Error is an error response: the HTTP status plus the code and message sent
+// to the client. Details, when set, maps request fields to their problems.
+type Error struct {
+	Status  int
+	Code    Code
+	Message string
+	Details map[string]string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.Status, e.Code, e.Message)
+}
+
+func Validation(message string) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: service.CodeValidation, Message: message}
+}
+
+// InvalidFields reports per-field validation problems.
+func InvalidFields(details map[string]string) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: service.CodeValidation, Message: "request validation failed", Details: details}
+}
+
+func BadRequest(message string) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: service.CodeBadRequest, Message: message}
+}
+
+func NotFound(message string) *Error {
+	return &Error{Status: http.StatusNotFound, Code: service.CodeNotFound, Message: message}
+}
+
+func Forbidden(message string) *Error {
+	return &Error{Status: http.StatusForbidden, Code: service.CodeForbidden, Message: message}
+}
+
+func Internal() *Error {
+	return &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Message: "internal server error"}
+}
+
+func Timeout() *Error {
+	return &Error{Status: http.StatusGatewayTimeout, Code: CodeTimeout, Message: "request timed out"}
+}
+
+// serviceStatus maps domain error codes to HTTP statuses.
+var serviceStatus = map[service.Code]int{
+	service.CodeValidation:         http.StatusBadRequest,
+	service.CodeBadRequest:         http.StatusBadRequest,
+	service.CodeNotFound:           http.StatusNotFound,
+	service.CodeTeamExists:         http.StatusBadRequest,
+	service.CodePRExists:           http.StatusConflict,
+	service.CodePRMerged:           http.StatusConflict,
+	service.CodeNotAssigned:        http.StatusConflict,
+	service.CodeNoCandidate:        http.StatusConflict,
+	service.CodePreconditionFailed: http.StatusPreconditionFailed,
+	service.CodeUnauthorized:       http.StatusUnauthorized,
+	service.CodeForbidden:          http.StatusForbidden,
+}
+
+// FromService converts a domain error. Unknown codes are reported as bad
+// requests.
+func FromService(err *service.Error) *Error {
+	status, ok := serviceStatus[err.Code]
+	if !ok {
+		status = http.StatusBadRequest
+	}
+	return &Error{Status: status, Code: err.Code, Message: err.Message}
+}
+
+// Response mirrors the ErrorResponse schema.
+type Response struct {
+	Error ResponseError `json:"error"`
+}
+
+type ResponseError struct {
+	Code      Code              `json:"code"`
+	Message   string            `json:"message"`
+	RequestID string            `json:"request_id"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// Write sends e as JSON with the ID of request r.
+func Write(w http.ResponseWriter, r *http.Request, e *Error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(e.Status)
+	resp := Response{Error: ResponseError{Code: e.Code, Message: e.Message, RequestID: RequestID(r.Context()), Details: e.Details}}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("failed to write error response: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package apierror
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reviewer-service/app/service"
+	"testing"
+)
+
+func TestWriteJSONWithRequestID(t *testing.T) {
+	h := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Write(w, r, NotFound("PR not found"))
+	}))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/pullRequest/list", nil)
+	req.Header.Set(RequestIDHeader, "client-id-1")
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("expected JSON content type, got %q", ct)
+	}
+	var body Response
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if body.Error.Code != service.CodeNotFound || body.Error.Message != "PR not found" || body.Error.RequestID != "client-id-1" {
+		t.Fatalf("unexpected body %+v", body)
+	}
+	if got := rec.Header().Get(RequestIDHeader); got != "client-id-1" {
+		t.Fatalf("expected request ID header to be echoed, got %q", got)
+	}
+}
+
+func TestRequestIDGeneratedForInvalidHeader(t *testing.T) {
+	var seen string
+	h := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestID(r.Context())
+	}))
+
+	req := httptest.NewRequest("GET", "/health/live", nil)
+	req.Header.Set(RequestIDHeader, "has spaces")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if len(seen) != 32 || seen != rec.Header().Get(RequestIDHeader) {
+		t.Fatalf("expected a generated 32 char ID, got %q (header %q)", seen, rec.Header().Get(RequestIDHeader))
+	}
+}
+
+func TestFromService(t *testing.T) {
+	cases := map[service.Code]int{
+		service.CodeValidation:         http.StatusBadRequest,
+		service.CodeNotFound:           http.StatusNotFound,
+		service.CodeTeamExists:         http.StatusBadRequest,
+		service.CodePRMerged:           http.StatusConflict,
+		service.CodeNoCandidate:        http.StatusConflict,
+		service.CodePreconditionFailed: http.StatusPreconditionFailed,
+	}
+	for code, want := range cases {
+		e := FromService(&service.Error{Code: code, Message: "m"})
+		if e.Status != want || e.Code != code {
+			t.Fatalf("%s: expected %d, got %d %s", code, want, e.Status, e.Code)
+		}
+	}
+}
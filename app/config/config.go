@@ -0,0 +1,346 @@
+// Package config loads service settings from defaults, an optional YAML file,
+// environment variables and command-line flags, in that order of precedence.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	HTTP  HTTPConfig  `yaml:"http"`
+	DB    DBConfig    `yaml:"db"`
+	Stats StatsConfig `yaml:"stats"`
+	Auth  AuthConfig  `yaml:"auth"`
+}
+
+type HTTPConfig struct {
+	Addr            string        `yaml:"addr"`
+	ReadTimeout     time.Duration `yaml:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// DrainDelay is how long readiness fails before the listener is closed.
+	DrainDelay time.Duration `yaml:"drain_delay"`
+	// QueryTimeout and StatsQueryTimeout bound database work per request; zero
+	// disables the deadline.
+	QueryTimeout      time.Duration `yaml:"query_timeout"`
+	StatsQueryTimeout time.Duration `yaml:"stats_query_timeout"`
+	// MaxBodyBytes caps the size of a JSON request body.
+	MaxBodyBytes int `yaml:"max_body_bytes"`
+	// IdempotencyTTL is how long a response stays stored under its
+	// Idempotency-Key.
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl"`
+}
+
+type DBConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"sslmode"`
+
+	MaxConns          int32         `yaml:"max_conns"`
+	MinConns          int32         `yaml:"min_conns"`
+	HealthCheckPeriod time.Duration `yaml:"health_check_period"`
+	MaxConnLifetime   time.Duration `yaml:"max_conn_lifetime"`
+	MaxConnIdleTime   time.Duration `yaml:"max_conn_idle_time"`
+
+	// AutoMigrate applies pending schema migrations when the pool is opened.
+	AutoMigrate bool `yaml:"auto_migrate"`
+}
+
+type StatsConfig struct {
+	// CacheTTL bounds how long /stats/assignments responses are reused; zero
+	// disables the cache.
+	CacheTTL              time.Duration `yaml:"cache_ttl"`
+	FairnessGiniThreshold float64       `yaml:"fairness_gini_threshold"`
+}
+
+type AuthConfig struct {
+	// Enabled requires a bearer token on every endpoint except the health
+	// checks; metrics need a token of any role.
+	Enabled bool `yaml:"enabled"`
+	// BootstrapToken, when set, is accepted as an admin token without being
+	// stored, so that the first tokens can be issued.
+	BootstrapToken string `yaml:"bootstrap_token"`
+}
+
+// Default returns the settings used when nothing else is configured.
+func Default() Config {
+	return Config{
+		HTTP: HTTPConfig{
+			Addr:              ":8080",
+			ReadTimeout:       10 * time.Second,
+			WriteTimeout:      10 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   20 * time.Second,
+			QueryTimeout:      3 * time.Second,
+			StatsQueryTimeout: 8 * time.Second,
+			MaxBodyBytes:      1 << 20,
+			IdempotencyTTL:    24 * time.Hour,
+		},
+		DB: DBConfig{
+			Host:              "localhost",
+			Port:              5432,
+			SSLMode:           "prefer",
+			MaxConns:          10,
+			MinConns:          0,
+			HealthCheckPeriod: time.Minute,
+			MaxConnLifetime:   time.Hour,
+			MaxConnIdleTime:   30 * time.Minute,
+			AutoMigrate:       true,
+		},
+		Stats: StatsConfig{
+			CacheTTL:              30 * time.Second,
+			FairnessGiniThreshold: 0.3,
+		},
+		Auth: AuthConfig{
+			Enabled: true,
+		},
+	}
+}
+
+// Load builds the configuration from defaults, the YAML file given by
+// -config or CONFIG_FILE, environment variables (including a .env file in
+// the working directory) and finally the flags in args.
+func Load(args []string) (Config, error) {
+	_ = godotenv.Load()
+	cfg := Default()
+
+	fs := flag.NewFlagSet("reviewer-service", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML config file")
+	addr := fs.String("addr", "", "HTTP listen address")
+	dbHost := fs.String("db-host", "", "database host")
+	dbPort := fs.Int("db-port", 0, "database port")
+	dbName := fs.String("db-name", "", "database name")
+	dbMaxConns := fs.Int("db-max-conns", 0, "maximum size of the connection pool")
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	if *configFile != "" {
+		if err := loadFile(&cfg, *configFile); err != nil {
+			return cfg, err
+		}
+	}
+	if err := applyEnv(&cfg); err != nil {
+		return cfg, err
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "addr":
+			cfg.HTTP.Addr = *addr
+		case "db-host":
+			cfg.DB.Host = *dbHost
+		case "db-port":
+			cfg.DB.Port = *dbPort
+		case "db-name":
+			cfg.DB.Name = *dbName
+		case "db-max-conns":
+			cfg.DB.MaxConns = int32(*dbMaxConns) //nolint:gosec // range checked by Validate
+		}
+	})
+
+	return cfg, cfg.Validate()
+}
+
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func applyEnv(cfg *Config) error {
+	strs := map[string]*string{
+		"HTTP_ADDR":            &cfg.HTTP.Addr,
+		"DB_HOST":              &cfg.DB.Host,
+		"DB_USER":              &cfg.DB.User,
+		"DB_PASSWORD":          &cfg.DB.Password,
+		"DB_NAME":              &cfg.DB.Name,
+		"DB_SSLMODE":           &cfg.DB.SSLMode,
+		"AUTH_BOOTSTRAP_TOKEN": &cfg.Auth.BootstrapToken,
+	}
+	durations := map[string]*time.Duration{
+		"HTTP_READ_TIMEOUT":      &cfg.HTTP.ReadTimeout,
+		"HTTP_WRITE_TIMEOUT":     &cfg.HTTP.WriteTimeout,
+		"HTTP_IDLE_TIMEOUT":      &cfg.HTTP.IdleTimeout,
+		"SHUTDOWN_TIMEOUT":       &cfg.HTTP.ShutdownTimeout,
+		"SHUTDOWN_DRAIN_DELAY":   &cfg.HTTP.DrainDelay,
+		"QUERY_TIMEOUT":          &cfg.HTTP.QueryTimeout,
+		"STATS_QUERY_TIMEOUT":    &cfg.HTTP.StatsQueryTimeout,
+		"IDEMPOTENCY_TTL":        &cfg.HTTP.IdempotencyTTL,
+		"DB_HEALTH_CHECK_PERIOD": &cfg.DB.HealthCheckPeriod,
+		"DB_MAX_CONN_LIFETIME":   &cfg.DB.MaxConnLifetime,
+		"DB_MAX_CONN_IDLE_TIME":  &cfg.DB.MaxConnIdleTime,
+		"STATS_CACHE_TTL":        &cfg.Stats.CacheTTL,
+	}
+	ints := map[string]*int{
+		"DB_PORT":             &cfg.DB.Port,
+		"HTTP_MAX_BODY_BYTES": &cfg.HTTP.MaxBodyBytes,
+	}
+	int32s := map[string]*int32{
+		"DB_MAX_CONNS": &cfg.DB.MaxConns,
+		"DB_MIN_CONNS": &cfg.DB.MinConns,
+	}
+	floats := map[string]*float64{
+		"FAIRNESS_GINI_THRESHOLD": &cfg.Stats.FairnessGiniThreshold,
+	}
+	bools := map[string]*bool{
+		"DB_AUTO_MIGRATE": &cfg.DB.AutoMigrate,
+		"AUTH_ENABLED":    &cfg.Auth.Enabled,
+	}
+
+	var errs []error
+	for name, dst := range strs {
+		if v, ok := os.LookupEnv(name); ok && v != "" {
+			*dst = v
+		}
+	}
+	for name, dst := range durations {
+		if v, ok := os.LookupEnv(name); ok && v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				continue
+			}
+			*dst = d
+		}
+	}
+	for name, dst := range ints {
+		if v, ok := os.LookupEnv(name); ok && v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				continue
+			}
+			*dst = n
+		}
+	}
+	for name, dst := range int32s {
+		if v, ok := os.LookupEnv(name); ok && v != "" {
+			n, err := strconv.ParseInt(v, 10, 32)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				continue
+			}
+			*dst = int32(n)
+		}
+	}
+	for name, dst := range floats {
+		if v, ok := os.LookupEnv(name); ok && v != "" {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				continue
+			}
+			*dst = f
+		}
+	}
+	for name, dst := range bools {
+		if v, ok := os.LookupEnv(name); ok && v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				continue
+			}
+			*dst = b
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// minBootstrapTokenLen keeps the bootstrap token from being guessable.
+const minBootstrapTokenLen = 16
+
+// bootstrapTokenPlaceholder is the value earlier versions of template.env
+// shipped with. It is public, so it must never work as an admin token.
+const bootstrapTokenPlaceholder = "change-me-to-a-long-random-string"
+
+// Validate reports every invalid setting at once.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	_, _, err := net.SplitHostPort(c.HTTP.Addr)
+	check(err == nil, "http.addr %q must be host:port", c.HTTP.Addr)
+	check(c.HTTP.ReadTimeout >= 0 && c.HTTP.WriteTimeout >= 0 && c.HTTP.IdleTimeout >= 0,
+		"http timeouts must not be negative")
+	check(c.HTTP.ShutdownTimeout > 0, "http.shutdown_timeout must be positive")
+	check(c.HTTP.DrainDelay >= 0, "http.drain_delay must not be negative")
+	check(c.HTTP.QueryTimeout >= 0 && c.HTTP.StatsQueryTimeout >= 0, "query timeouts must not be negative")
+	check(c.HTTP.MaxBodyBytes > 0, "http.max_body_bytes must be positive")
+	check(c.HTTP.IdempotencyTTL > 0, "http.idempotency_ttl must be positive")
+	if c.HTTP.WriteTimeout > 0 {
+		check(c.HTTP.QueryTimeout < c.HTTP.WriteTimeout && c.HTTP.StatsQueryTimeout < c.HTTP.WriteTimeout,
+			"query timeouts must be shorter than http.write_timeout so that TIMEOUT responses can be written")
+	}
+
+	check(c.DB.Host != "", "db.host is required")
+	check(c.DB.Port > 0 && c.DB.Port <= 65535, "db.port %d is out of range", c.DB.Port)
+	check(c.DB.User != "", "db.user is required")
+	check(c.DB.Name != "", "db.name is required")
+	check(c.DB.MaxConns >= 1, "db.max_conns must be at least 1")
+	check(c.DB.MinConns >= 0 && c.DB.MinConns <= c.DB.MaxConns, "db.min_conns must be between 0 and db.max_conns")
+	check(c.DB.HealthCheckPeriod > 0, "db.health_check_period must be positive")
+	check(c.DB.MaxConnLifetime >= 0 && c.DB.MaxConnIdleTime >= 0, "db connection lifetimes must not be negative")
+
+	check(c.Stats.CacheTTL >= 0, "stats.cache_ttl must not be negative")
+	check(c.Stats.FairnessGiniThreshold >= 0 && c.Stats.FairnessGiniThreshold <= 1,
+		"stats.fairness_gini_threshold must be between 0 and 1")
+
+	check(c.Auth.BootstrapToken == "" || len(c.Auth.BootstrapToken) >= minBootstrapTokenLen,
+		"auth.bootstrap_token must be at least %d characters", minBootstrapTokenLen)
+	check(c.Auth.BootstrapToken != bootstrapTokenPlaceholder,
+		"auth.bootstrap_token must be replaced with a random string")
+
+	return errors.Join(errs...)
+}
+
+// Redacted renders the configuration as YAML with secrets masked, for logging
+// at startup.
+func (c Config) Redacted() string {
+	if c.DB.Password != "" {
+		c.DB.Password = "*****"
+	}
+	if c.Auth.BootstrapToken != "" {
+		c.Auth.BootstrapToken = "*****"
+	}
+	out, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Sprintf("<failed to render config: %v>", err)
+	}
+	return string(out)
+}
+
+// DSN is the connection string for the configured database.
+func (c DBConfig) DSN() string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.User, c.Password),
+		Host:     net.JoinHostPort(c.Host, strconv.Itoa(c.Port)),
+		Path:     "/" + c.Name,
+		RawQuery: url.Values{"sslmode": {c.SSLMode}}.Encode(),
+	}
+	return u.String()
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setRequiredEnv(t *testing.T) {
+	t.Setenv("DB_USER", "reviewer")
+	t.Setenv("DB_PASSWORD", "s3cret")
+	t.Setenv("DB_NAME", "reviewer_db")
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	setRequiredEnv(t)
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	file := `
+http:
+  addr: ":9000"
+  query_timeout: 2s
+db:
+  host: file-host
+  max_conns: 5
+stats:
+  cache_ttl: 1m
+`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DB_HOST", "env-host")
+	t.Setenv("DB_MAX_CONNS", "7")
+
+	cfg, err := Load([]string{"-config", path, "-db-max-conns", "9"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.HTTP.Addr != ":9000" || cfg.HTTP.QueryTimeout != 2*time.Second || cfg.Stats.CacheTTL != time.Minute {
+		t.Fatalf("expected values from file, got %+v", cfg)
+	}
+	if cfg.DB.Host != "env-host" {
+		t.Fatalf("expected env to override file, got host %q", cfg.DB.Host)
+	}
+	if cfg.DB.MaxConns != 9 {
+		t.Fatalf("expected flag to override env, got max_conns %d", cfg.DB.MaxConns)
+	}
+	if cfg.HTTP.WriteTimeout != 10*time.Second {
+		t.Fatalf("expected default write timeout, got %s", cfg.HTTP.WriteTimeout)
+	}
+}
+
+func TestLoadInvalidEnv(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("QUERY_TIMEOUT", "soon")
+
+	if _, err := Load(nil); err == nil || !strings.Contains(err.Error(), "QUERY_TIMEOUT") {
+		t.Fatalf("expected QUERY_TIMEOUT error, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Default()
+	cfg.DB.User, cfg.DB.Name = "reviewer", "reviewer_db"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected defaults to be valid, got %v", err)
+	}
+
+	cfg.DB.MinConns = cfg.DB.MaxConns + 1
+	cfg.HTTP.StatsQueryTimeout = cfg.HTTP.WriteTimeout
+	cfg.Stats.FairnessGiniThreshold = 1.5
+	cfg.Auth.BootstrapToken = "short"
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, want := range []string{"db.min_conns", "write_timeout", "fairness_gini_threshold", "bootstrap_token"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error mentioning %s, got %v", want, err)
+		}
+	}
+}
+
+func TestValidateRejectsBootstrapPlaceholder(t *testing.T) {
+	cfg := Default()
+	cfg.DB.User, cfg.DB.Name = "reviewer", "reviewer_db"
+	cfg.Auth.BootstrapToken = bootstrapTokenPlaceholder
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "bootstrap_token") {
+		t.Fatalf("expected the template placeholder to be rejected, got %v", err)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := Default()
+	cfg.DB.Password = "s3cret"
+	cfg.Auth.BootstrapToken = "bootstrap-s3cret"
+
+	out := cfg.Redacted()
+	if strings.Contains(out, "s3cret") {
+		t.Fatalf("secret leaked into dump:\n%s", out)
+	}
+	if cfg.DB.Password != "s3cret" {
+		t.Fatal("Redacted must not modify the config")
+	}
+	if !strings.Contains(cfg.DB.DSN(), "s3cret") {
+		t.Fatal("expected DSN to contain the password")
+	}
+}
@@ -4,30 +4,43 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
+	"reviewer-service/app/config"
 
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/joho/godotenv"
 )
 
 var Pool *pgxpool.Pool
 
-func Init() error {
-	_ = godotenv.Load()
+// SchemaVersion is the newest migration the code relies on; bump it together
+// with every new file pair in migrations/.
+const SchemaVersion = 8
 
-	user := os.Getenv("DB_USER")
-	pass := os.Getenv("DB_PASSWORD")
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	dbname := os.Getenv("DB_NAME")
-
-	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s", user, pass, host, port, dbname)
+func Init(cfg config.DBConfig) error {
+	poolCfg, err := pgxpool.ParseConfig(cfg.DSN())
+	if err != nil {
+		return fmt.Errorf("invalid database config: %w", err)
+	}
+	poolCfg.MaxConns = cfg.MaxConns
+	poolCfg.MinConns = cfg.MinConns
+	poolCfg.HealthCheckPeriod = cfg.HealthCheckPeriod
+	poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
+	poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
 
-	pool, err := pgxpool.New(context.Background(), dsn)
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolCfg)
 	if err != nil {
 		return fmt.Errorf("failed to connect to DB: %w", err)
 	}
 	Pool = pool
+
+	if cfg.AutoMigrate {
+		applied, err := MigrateUp(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to apply migrations: %w", err)
+		}
+		for _, m := range applied {
+			log.Printf("Applied migration %d_%s", m.Version, m.Name)
+		}
+	}
 	return nil
 }
 
@@ -43,6 +56,10 @@ func ClearAllTables() error {
 	}
 
 	tables := []string{
+		"idempotency_keys",
+		"api_tokens",
+		"audit_log",
+		"review_assignments",
 		"pull_requests",
 		"users",
 		"teams",
@@ -70,3 +87,20 @@ func ClearAllTables() error {
 
 	return nil
 }
+
+// CheckSchemaVersion verifies that the schema the code expects has been
+// applied to the database behind pool.
+func CheckSchemaVersion(ctx context.Context, pool *pgxpool.Pool) error {
+	if pool == nil {
+		return fmt.Errorf("database pool is not initialized")
+	}
+
+	var version int
+	if err := pool.QueryRow(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version < SchemaVersion {
+		return fmt.Errorf("schema version %d is older than expected %d", version, SchemaVersion)
+	}
+	return nil
+}
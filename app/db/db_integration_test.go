@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"reviewer-service/app/config"
 	"reviewer-service/app/testutils"
 )
 
@@ -11,7 +12,11 @@ func setupDB(t *testing.T) {
 	if err := testutils.LoadTestEnv("../../.env"); err != nil {
 		t.Fatalf("failed to load env: %v", err)
 	}
-	if err := Init(); err != nil {
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatalf("invalid config: %v", err)
+	}
+	if err := Init(cfg.DB); err != nil {
 		t.Fatalf("failed to init DB: %v", err)
 	}
 	t.Cleanup(func() { Close() })
@@ -38,6 +43,67 @@ func TestTableExists(t *testing.T) {
 	}
 }
 
+func TestSchemaVersion(t *testing.T) {
+	setupDB(t)
+
+	if err := CheckSchemaVersion(context.Background(), Pool); err != nil {
+		t.Fatalf("expected schema version %d to be applied: %v", SchemaVersion, err)
+	}
+}
+
+func TestMigrationStatus(t *testing.T) {
+	setupDB(t)
+
+	states, err := MigrationStatus(context.Background())
+	if err != nil {
+		t.Fatalf("failed to read migration status: %v", err)
+	}
+	for _, s := range states {
+		if s.AppliedAt == nil {
+			t.Errorf("expected migration %d_%s to be applied by Init", s.Version, s.Name)
+		}
+	}
+
+	applied, err := MigrateUp(context.Background())
+	if err != nil || len(applied) != 0 {
+		t.Fatalf("expected no pending migrations, got %v, err=%v", applied, err)
+	}
+}
+
+// TestMigrateFromBaseline upgrades a database that only has the tables of the
+// old initdb script and expects the reviewers of existing PRs to get open
+// assignments.
+func TestMigrateFromBaseline(t *testing.T) {
+	setupDB(t)
+	ctx := context.Background()
+
+	if _, err := MigrateDown(ctx, SchemaVersion-1); err != nil {
+		t.Fatalf("failed to revert to the baseline: %v", err)
+	}
+	for _, sql := range []string{
+		"INSERT INTO teams (team_name) VALUES ('backend')",
+		"INSERT INTO users (user_id, username, team_name) VALUES ('u1', 'Alice', 'backend'), ('u2', 'Bob', 'backend')",
+		`INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, assigned_reviewers)
+		VALUES ('pr-old', 'Old PR', 'u1', 'OPEN', '{u2}')`,
+	} {
+		if _, err := Pool.Exec(ctx, sql); err != nil {
+			t.Fatalf("failed to seed baseline data: %v", err)
+		}
+	}
+	if _, err := MigrateUp(ctx); err != nil {
+		t.Fatalf("failed to migrate up: %v", err)
+	}
+
+	var count int
+	err := Pool.QueryRow(ctx, `
+		SELECT count(*) FROM review_assignments
+		WHERE pull_request_id='pr-old' AND reviewer_id='u2' AND unassigned_at IS NULL
+	`).Scan(&count)
+	if err != nil || count != 1 {
+		t.Fatalf("expected one open assignment for u2, got count=%d, err=%v", count, err)
+	}
+}
+
 func TestInsertAndSelectPR(t *testing.T) {
 	setupDB(t)
 
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("expected embedded migrations")
+	}
+	if last := migrations[len(migrations)-1].Version; last != SchemaVersion {
+		t.Fatalf("SchemaVersion is %d but the newest migration is %d", SchemaVersion, last)
+	}
+	for _, m := range migrations {
+		if m.Down == "" {
+			t.Errorf("migration %d_%s has no down file", m.Version, m.Name)
+		}
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0002_add_index.up.sql":   {Data: []byte("CREATE INDEX i ON t (c);")},
+		"m/0001_init.up.sql":        {Data: []byte("CREATE TABLE t (c INT);")},
+		"m/0001_init.down.sql":      {Data: []byte("DROP TABLE t;")},
+		"m/0002_add_index.down.sql": {Data: []byte("DROP INDEX i;")},
+	}
+	migrations, err := loadMigrations(fsys, "m")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(migrations) != 2 || migrations[0].Version != 1 || migrations[1].Name != "add_index" {
+		t.Fatalf("unexpected migrations: %+v", migrations)
+	}
+
+	missingUp := fstest.MapFS{"m/0003_only_down.down.sql": {Data: []byte("SELECT 1;")}}
+	if _, err := loadMigrations(missingUp, "m"); err == nil {
+		t.Fatal("expected error for migration without up file")
+	}
+
+	badName := fstest.MapFS{"m/init.sql": {Data: []byte("SELECT 1;")}}
+	if _, err := loadMigrations(badName, "m"); err == nil {
+		t.Fatal("expected error for unexpected file name")
+	}
+}
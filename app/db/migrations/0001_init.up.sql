@@ -1,3 +1,7 @@
+-- The schema of the old docker-entrypoint-initdb.d script. Databases created
+-- by it already contain these tables, and later migrations add what the code
+-- gained since; their schema_version table is superseded by
+-- schema_migrations.
 CREATE TABLE IF NOT EXISTS teams (
     team_name TEXT PRIMARY KEY
 );
@@ -17,4 +21,6 @@ CREATE TABLE IF NOT EXISTS pull_requests (
     assigned_reviewers TEXT[],
     created_at TIMESTAMPTZ DEFAULT NOW(),
     merged_at TIMESTAMPTZ
-);  
\ No newline at end of file
+);
+
+DROP TABLE IF EXISTS schema_version;
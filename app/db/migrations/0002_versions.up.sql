@@ -0,0 +1,5 @@
+-- Row versions back the ETag and If-Match headers. They only ever grow: the
+-- repositories increment them on every change to the row, or for teams to
+-- their member list.
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,12 @@
+-- API tokens. Only the SHA-256 of a secret is stored; a team lead's token is
+-- bound to the team it manages.
+CREATE TABLE IF NOT EXISTS api_tokens (
+    token_id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('admin', 'team-lead', 'bot', 'read-only')),
+    team_name TEXT REFERENCES teams(team_name),
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMPTZ,
+    CHECK ((role = 'team-lead') = (team_name IS NOT NULL))
+);
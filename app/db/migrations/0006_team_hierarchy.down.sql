@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS team_fallbacks;
+ALTER TABLE teams
+    DROP COLUMN IF EXISTS add_junior_reviewer,
+    DROP COLUMN IF EXISTS require_senior,
+    DROP COLUMN IF EXISTS review_sla_hours,
+    DROP COLUMN IF EXISTS reviewer_count,
+    DROP COLUMN IF EXISTS kind,
+    DROP COLUMN IF EXISTS parent_team;
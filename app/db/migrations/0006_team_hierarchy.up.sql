@@ -0,0 +1,18 @@
+-- Team hierarchy, inherited review policies and partner teams used as a
+-- fallback for reviewer selection. NULL policy columns inherit the value of
+-- the parent team.
+ALTER TABLE teams
+    ADD COLUMN IF NOT EXISTS parent_team TEXT REFERENCES teams(team_name) CHECK (parent_team <> team_name),
+    ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'team' CHECK (kind IN ('department', 'team', 'squad')),
+    ADD COLUMN IF NOT EXISTS reviewer_count INT CHECK (reviewer_count >= 0),
+    ADD COLUMN IF NOT EXISTS review_sla_hours INT CHECK (review_sla_hours > 0),
+    ADD COLUMN IF NOT EXISTS require_senior BOOLEAN,
+    ADD COLUMN IF NOT EXISTS add_junior_reviewer BOOLEAN;
+
+CREATE TABLE IF NOT EXISTS team_fallbacks (
+    team_name TEXT NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    fallback_team TEXT NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    priority INT NOT NULL,
+    PRIMARY KEY (team_name, fallback_team),
+    CHECK (team_name <> fallback_team)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS seniority TEXT NOT NULL DEFAULT 'middle' CHECK (seniority IN ('junior', 'middle', 'senior'));
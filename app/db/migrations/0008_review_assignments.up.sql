@@ -0,0 +1,27 @@
+-- Assignment history with review verdicts. A row is open while unassigned_at
+-- is NULL; pull_requests.assigned_reviewers lists the open ones.
+CREATE TABLE IF NOT EXISTS review_assignments (
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    reviewer_id TEXT NOT NULL REFERENCES users(user_id),
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    unassigned_at TIMESTAMPTZ,
+    verdict TEXT CHECK (verdict IN ('APPROVED', 'CHANGES_REQUESTED')),
+    verdict_at TIMESTAMPTZ,
+    first_verdict_at TIMESTAMPTZ,
+    PRIMARY KEY (pull_request_id, reviewer_id, assigned_at)
+);
+
+CREATE INDEX IF NOT EXISTS review_assignments_assigned_at_idx ON review_assignments (assigned_at);
+
+-- PRs created before the table existed get an open row for every current
+-- reviewer, otherwise those reviewers could not submit a verdict. The
+-- creation time is the best known assignment time.
+INSERT INTO review_assignments (pull_request_id, reviewer_id, assigned_at)
+SELECT DISTINCT p.pull_request_id, r.reviewer_id, COALESCE(p.created_at, NOW())
+FROM pull_requests p
+CROSS JOIN unnest(p.assigned_reviewers) AS r(reviewer_id)
+JOIN users u ON u.user_id = r.reviewer_id
+WHERE NOT EXISTS (
+    SELECT 1 FROM review_assignments a
+    WHERE a.pull_request_id = p.pull_request_id AND a.reviewer_id = r.reviewer_id AND a.unassigned_at IS NULL
+);
@@ -3,35 +3,83 @@ package e2e
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 
+	"reviewer-service/app/config"
 	"reviewer-service/app/db"
+	"reviewer-service/app/handlers"
+	"reviewer-service/app/repository/memory"
 	"reviewer-service/app/testutils"
 )
 
 var baseURL string
 
+// inProcess is set when the suite runs against an httptest server backed by
+// the in-memory store instead of a deployed app.
+var inProcess bool
+
+// e2eBootstrapToken is the admin token of the in-process server. Against a
+// deployed app the suite uses AUTH_BOOTSTRAP_TOKEN instead.
+const e2eBootstrapToken = "e2e-bootstrap-token"
+
+var apiToken string
+
+// apiClient sends every request with apiToken unless the request carries its
+// own Authorization header.
+var apiClient = &http.Client{Transport: tokenTransport{}}
+
+type tokenTransport struct{}
+
+func (tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+apiToken)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
 func TestMain(m *testing.M) {
-	if err := testutils.LoadTestEnv("../../.env"); err != nil {
-		log.Fatalf("failed to load env: %v", err)
+	// An explicit APP_HOST (even empty) wins over .env; without a host the
+	// suite runs in-process.
+	if _, ok := os.LookupEnv("APP_HOST"); !ok {
+		_ = testutils.LoadTestEnv("../../.env")
 	}
 
-	if err := db.Init(); err != nil {
+	appHost := os.Getenv("APP_HOST")
+	if appHost == "" {
+		inProcess = true
+		cfg := config.Default()
+		cfg.Auth.BootstrapToken = e2eBootstrapToken
+		apiToken = e2eBootstrapToken
+		srv := httptest.NewServer(handlers.New(memory.New(), cfg).Router(handlers.DefaultQueryTimeouts(cfg.HTTP)))
+		baseURL = srv.URL
+		code := m.Run()
+		srv.Close()
+		os.Exit(code)
+	}
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		log.Fatalf("invalid config: %v", err)
+	}
+	if err := db.Init(cfg.DB); err != nil {
 		panic(err)
 	}
 	if err := db.ClearAllTables(); err != nil {
 		log.Fatalf("Failed to clear database: %v", err)
 	}
-	appHost := os.Getenv("APP_HOST")
-	if appHost == "" {
-		appHost = "localhost"
-	}
 	baseURL = fmt.Sprintf("http://%s:8080", appHost)
+	apiToken = cfg.Auth.BootstrapToken
 
 	db.Close()
 	os.Exit(m.Run())
@@ -50,8 +98,7 @@ func postJSON(t *testing.T, path string, payload any) *http.Response {
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := apiClient.Do(req)
 	if err != nil {
 		t.Fatalf("POST %s failed: %v", path, err)
 	}
@@ -65,8 +112,7 @@ func getJSON(t *testing.T, path string) *http.Response {
 		t.Fatalf("failed to create GET request: %v", err)
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := apiClient.Do(req)
 	if err != nil {
 		t.Fatalf("GET %s failed: %v", path, err)
 	}
@@ -189,3 +235,1261 @@ func TestE2E_ReassignReviewer(t *testing.T) {
 		t.Fatalf("expected PR pr-2001 to be assigned to u4")
 	}
 }
+
+func TestE2E_CrossTeamFallback(t *testing.T) {
+	resp := postJSON(t, "/team/add", map[string]any{
+		"team_name": "infra",
+		"members": []map[string]any{
+			{"user_id": "u5", "username": "Eve", "is_active": true},
+		},
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	resp = postJSON(t, "/team/add", map[string]any{
+		"team_name": "solo",
+		"members": []map[string]any{
+			{"user_id": "u6", "username": "Frank", "is_active": true},
+		},
+		"fallback_teams": []string{"infra"},
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	resp = postJSON(t, "/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-3001",
+		"pull_request_name": "Lonely change",
+		"author_id":         "u6",
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	var result struct {
+		PR struct {
+			AssignedReviewers []string `json:"assigned_reviewers"`
+		} `json:"pr"`
+		CrossTeam          bool     `json:"cross_team"`
+		CrossTeamReviewers []string `json:"cross_team_reviewers"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(result.PR.AssignedReviewers) != 1 || result.PR.AssignedReviewers[0] != "u5" {
+		t.Fatalf("expected u5 from fallback team, got %v", result.PR.AssignedReviewers)
+	}
+	if !result.CrossTeam || len(result.CrossTeamReviewers) != 1 {
+		t.Fatalf("expected cross-team flag, got %+v", result)
+	}
+}
+
+func TestE2E_TeamHierarchy(t *testing.T) {
+	resp := postJSON(t, "/team/add", map[string]any{
+		"team_name": "eng",
+		"kind":      "department",
+		"members":   []map[string]any{},
+		"policy":    map[string]any{"reviewer_count": 1},
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	resp = postJSON(t, "/team/add", map[string]any{
+		"team_name":   "platform",
+		"parent_team": "eng",
+		"members": []map[string]any{
+			{"user_id": "p1", "username": "Grace", "is_active": true},
+			{"user_id": "p2", "username": "Heidi", "is_active": true},
+			{"user_id": "p3", "username": "Ivan", "is_active": true},
+		},
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	resp = postJSON(t, "/team/add", map[string]any{
+		"team_name": "core",
+		"kind":      "squad",
+		"members": []map[string]any{
+			{"user_id": "c1", "username": "Judy", "is_active": true},
+		},
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	resp = postJSON(t, "/team/update", map[string]any{"team_name": "core", "parent_team": "platform"})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	resp = postJSON(t, "/team/update", map[string]any{"team_name": "eng", "parent_team": "core"})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for hierarchy cycle, got %d", resp.StatusCode)
+	}
+
+	resp = postJSON(t, "/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-4001",
+		"pull_request_name": "Squad change",
+		"author_id":         "c1",
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	var created struct {
+		PR struct {
+			AssignedReviewers []string `json:"assigned_reviewers"`
+		} `json:"pr"`
+		CrossTeam bool `json:"cross_team"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(created.PR.AssignedReviewers) != 1 || !created.CrossTeam {
+		t.Fatalf("expected one inherited cross-team reviewer, got %+v", created)
+	}
+
+	resp = getJSON(t, "/team/tree?team_name=eng")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var tree struct {
+		Subteams []struct {
+			TeamName        string `json:"team_name"`
+			EffectivePolicy struct {
+				ReviewerCount     int    `json:"reviewer_count"`
+				ReviewerCountFrom string `json:"reviewer_count_from"`
+			} `json:"effective_policy"`
+			Subteams []struct {
+				TeamName string   `json:"team_name"`
+				Path     []string `json:"path"`
+			} `json:"subteams"`
+		} `json:"subteams"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tree); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(tree.Subteams) != 1 || tree.Subteams[0].TeamName != "platform" {
+		t.Fatalf("expected platform under eng, got %+v", tree)
+	}
+	if tree.Subteams[0].EffectivePolicy.ReviewerCount != 1 || tree.Subteams[0].EffectivePolicy.ReviewerCountFrom != "eng" {
+		t.Fatalf("expected reviewer_count inherited from eng, got %+v", tree.Subteams[0].EffectivePolicy)
+	}
+	if len(tree.Subteams[0].Subteams) != 1 || len(tree.Subteams[0].Subteams[0].Path) != 3 {
+		t.Fatalf("expected core squad with path of 3, got %+v", tree.Subteams[0].Subteams)
+	}
+
+	resp = getJSON(t, "/stats/assignments/tree?team_name=eng")
+	defer resp.Body.Close()
+	var stats struct {
+		TotalAssignedPRCount int `json:"total_assigned_pr_count"`
+		TotalMemberCount     int `json:"total_member_count"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if stats.TotalAssignedPRCount != 1 || stats.TotalMemberCount != 4 {
+		t.Fatalf("expected rolled up stats 1/4, got %+v", stats)
+	}
+}
+
+func TestE2E_MentorshipComposition(t *testing.T) {
+	resp := postJSON(t, "/team/add", map[string]any{
+		"team_name": "mentors",
+		"members": []map[string]any{
+			{"user_id": "m1", "username": "Ken", "is_active": true},
+			{"user_id": "m2", "username": "Liam", "is_active": true},
+			{"user_id": "m3", "username": "Mia", "is_active": true, "seniority": "senior"},
+			{"user_id": "m4", "username": "Noah", "is_active": true, "seniority": "junior"},
+		},
+		"policy": map[string]any{"reviewer_count": 1, "require_senior": true, "add_junior_reviewer": true},
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	resp = postJSON(t, "/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-5001",
+		"pull_request_name": "Mentored change",
+		"author_id":         "m1",
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	var result struct {
+		PR struct {
+			AssignedReviewers []string `json:"assigned_reviewers"`
+		} `json:"pr"`
+		LearningReviewers   []string `json:"learning_reviewers"`
+		CompositionWarnings []string `json:"composition_warnings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	reviewers := result.PR.AssignedReviewers
+	if len(reviewers) != 2 || reviewers[0] != "m3" || reviewers[1] != "m4" {
+		t.Fatalf("expected senior m3 and learning junior m4, got %v", reviewers)
+	}
+	if len(result.LearningReviewers) != 1 || len(result.CompositionWarnings) != 0 {
+		t.Fatalf("unexpected composition result: %+v", result)
+	}
+
+	resp = postJSON(t, "/users/setSeniority", map[string]any{"user_id": "m3", "seniority": "middle"})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	resp = postJSON(t, "/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-5002",
+		"pull_request_name": "Unmentored change",
+		"author_id":         "m1",
+	})
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(result.CompositionWarnings) != 1 {
+		t.Fatalf("expected a missing senior warning, got %v", result.CompositionWarnings)
+	}
+}
+
+func TestE2E_ExplainAssignment(t *testing.T) {
+	resp := postJSON(t, "/team/add", map[string]any{
+		"team_name": "explain",
+		"members": []map[string]any{
+			{"user_id": "x1", "username": "Olga", "is_active": true},
+			{"user_id": "x2", "username": "Pavel", "is_active": false},
+			{"user_id": "x3", "username": "Quinn", "is_active": true},
+		},
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	resp = postJSON(t, "/pullRequest/explainAssignment", map[string]any{"author_id": "x1"})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Explanation struct {
+			Candidates []struct {
+				UserID   string `json:"user_id"`
+				Decision string `json:"decision"`
+				Reason   string `json:"reason"`
+			} `json:"candidates"`
+			Selected []string `json:"selected"`
+		} `json:"explanation"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	reasons := map[string]string{}
+	for _, c := range result.Explanation.Candidates {
+		reasons[c.UserID] = c.Decision + "/" + c.Reason
+	}
+	expected := map[string]string{
+		"x1": "excluded/author",
+		"x2": "excluded/inactive",
+		"x3": "selected/candidate_order",
+	}
+	for userID, want := range expected {
+		if reasons[userID] != want {
+			t.Fatalf("expected %s to be %s, got %q", userID, want, reasons[userID])
+		}
+	}
+	if len(result.Explanation.Selected) != 1 || result.Explanation.Selected[0] != "x3" {
+		t.Fatalf("expected x3 to be selected, got %v", result.Explanation.Selected)
+	}
+
+	resp = postJSON(t, "/pullRequest/create?verbose=true", map[string]any{
+		"pull_request_id":   "pr-6001",
+		"pull_request_name": "Explained change",
+		"author_id":         "x1",
+	})
+	defer resp.Body.Close()
+	var created struct {
+		Explanation *struct {
+			Operation string `json:"operation"`
+		} `json:"explanation"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.Explanation == nil || created.Explanation.Operation != "create" {
+		t.Fatalf("expected verbose create to include explanation")
+	}
+}
+
+func TestE2E_SimulateAssignments(t *testing.T) {
+	resp := postJSON(t, "/team/add", map[string]any{
+		"team_name": "sim",
+		"members": []map[string]any{
+			{"user_id": "s1", "username": "Rita", "is_active": true},
+			{"user_id": "s2", "username": "Sam", "is_active": true},
+			{"user_id": "s3", "username": "Tom", "is_active": true},
+		},
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	for _, id := range []string{"pr-7001", "pr-7002"} {
+		resp = postJSON(t, "/pullRequest/create", map[string]any{
+			"pull_request_id":   id,
+			"pull_request_name": "Simulated change",
+			"author_id":         "s1",
+		})
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("expected 201, got %d", resp.StatusCode)
+		}
+	}
+
+	resp = postJSON(t, "/stats/simulate", map[string]any{"strategy": "least_loaded", "reviewer_count": 1})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var result struct {
+		PullRequestCount int `json:"pull_request_count"`
+		Users            []struct {
+			UserID         string `json:"user_id"`
+			ActualCount    int    `json:"actual_count"`
+			SimulatedCount int    `json:"simulated_count"`
+		} `json:"users"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.PullRequestCount < 2 {
+		t.Fatalf("expected replayed PRs, got %d", result.PullRequestCount)
+	}
+
+	for _, u := range result.Users {
+		if (u.UserID == "s2" || u.UserID == "s3") && (u.ActualCount != 2 || u.SimulatedCount != 1) {
+			t.Fatalf("expected %s to have 2 actual and 1 simulated review, got %+v", u.UserID, u)
+		}
+	}
+
+	resp = postJSON(t, "/stats/simulate", map[string]any{"strategy": "unknown"})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestE2E_AssignmentStatsFilters(t *testing.T) {
+	resp := postJSON(t, "/team/add", map[string]any{
+		"team_name": "stats",
+		"members": []map[string]any{
+			{"user_id": "st1", "username": "Uma", "is_active": true},
+			{"user_id": "st2", "username": "Vera", "is_active": true},
+			{"user_id": "st3", "username": "Walt", "is_active": true},
+		},
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	for _, id := range []string{"pr-8001", "pr-8002"} {
+		resp = postJSON(t, "/pullRequest/create", map[string]any{
+			"pull_request_id":   id,
+			"pull_request_name": "Stats change",
+			"author_id":         "st1",
+		})
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("expected 201, got %d", resp.StatusCode)
+		}
+	}
+
+	resp = postJSON(t, "/pullRequest/merge", map[string]any{"pull_request_id": "pr-8001"})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	resp = getJSON(t, "/stats/assignments?team=stats&status=open")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Users []struct {
+			UserID          string `json:"user_id"`
+			AssignedPRCount int    `json:"assigned_pr_count"`
+		} `json:"users"`
+		Teams []struct {
+			TeamName             string  `json:"team_name"`
+			MemberCount          int     `json:"member_count"`
+			TotalAssignedPRCount int     `json:"total_assigned_pr_count"`
+			AvgAssignedPRCount   float64 `json:"avg_assigned_pr_count"`
+		} `json:"teams"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(result.Users) != 3 {
+		t.Fatalf("expected only members of stats team, got %+v", result.Users)
+	}
+	if len(result.Teams) != 1 || result.Teams[0].TotalAssignedPRCount != 2 || result.Teams[0].MemberCount != 3 {
+		t.Fatalf("expected open totals of 2 over 3 members, got %+v", result.Teams)
+	}
+
+	resp = getJSON(t, "/stats/assignments?status=unknown")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestE2E_ReviewLatencyAndMergeTime(t *testing.T) {
+	resp := postJSON(t, "/team/add", map[string]any{
+		"team_name": "latency",
+		"members": []map[string]any{
+			{"user_id": "lt1", "username": "Xena", "is_active": true},
+			{"user_id": "lt2", "username": "Yuri", "is_active": true},
+		},
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	resp = postJSON(t, "/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-9001",
+		"pull_request_name": "Latency change",
+		"author_id":         "lt1",
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	resp = postJSON(t, "/pullRequest/submitReview", map[string]any{
+		"pull_request_id": "pr-9001",
+		"reviewer_id":     "lt2",
+		"verdict":         "LGTM",
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown verdict, got %d", resp.StatusCode)
+	}
+
+	resp = postJSON(t, "/pullRequest/submitReview", map[string]any{
+		"pull_request_id": "pr-9001",
+		"reviewer_id":     "lt1",
+		"verdict":         "APPROVED",
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected 409 for author review, got %d", resp.StatusCode)
+	}
+
+	resp = postJSON(t, "/pullRequest/submitReview", map[string]any{
+		"pull_request_id": "pr-9001",
+		"reviewer_id":     "lt2",
+		"verdict":         "APPROVED",
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	resp = postJSON(t, "/pullRequest/merge", map[string]any{"pull_request_id": "pr-9001"})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	resp = getJSON(t, "/stats/reviewLatency")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	var latency []struct {
+		ReviewerID    string   `json:"reviewer_id"`
+		ReviewedCount int      `json:"reviewed_count"`
+		P50Seconds    *float64 `json:"p50_seconds"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&latency); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	found := false
+	for _, s := range latency {
+		if s.ReviewerID == "lt2" {
+			found = true
+			if s.ReviewedCount != 1 || s.P50Seconds == nil {
+				t.Fatalf("expected one reviewed assignment for lt2, got %+v", s)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected latency stats for lt2, got %+v", latency)
+	}
+
+	resp = getJSON(t, "/stats/mergeTime")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	var mergeTime struct {
+		Teams []struct {
+			TeamName    string `json:"team_name"`
+			MergedCount int    `json:"merged_count"`
+		} `json:"teams"`
+		Authors []struct {
+			AuthorID    string `json:"author_id"`
+			MergedCount int    `json:"merged_count"`
+		} `json:"authors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&mergeTime); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	found = false
+	for _, g := range mergeTime.Authors {
+		if g.AuthorID == "lt1" && g.MergedCount == 1 {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected merge time for author lt1, got %+v", mergeTime.Authors)
+	}
+
+	resp = getJSON(t, "/stats/trends?from=not-a-date")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestE2E_FairnessStats(t *testing.T) {
+	resp := postJSON(t, "/team/add", map[string]any{
+		"team_name": "fairness",
+		"members": []map[string]any{
+			{"user_id": "fa1", "username": "Zack", "is_active": true},
+			{"user_id": "fa2", "username": "Amy", "is_active": true},
+			{"user_id": "fa3", "username": "Ben", "is_active": true},
+		},
+		"policy": map[string]any{"reviewer_count": 1},
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	resp = postJSON(t, "/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-9101",
+		"pull_request_name": "Fairness change",
+		"author_id":         "fa1",
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	resp = getJSON(t, "/stats/fairness?team=fairness&threshold=0.1")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var result struct {
+		GiniThreshold float64 `json:"gini_threshold"`
+		Teams         []struct {
+			TeamName    string `json:"team_name"`
+			MemberCount int    `json:"member_count"`
+			Assignments struct {
+				Gini float64 `json:"gini"`
+				Max  int     `json:"max"`
+			} `json:"assignments"`
+			Imbalanced bool `json:"imbalanced"`
+		} `json:"teams"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(result.Teams) != 1 || result.Teams[0].MemberCount != 3 || result.Teams[0].Assignments.Max != 1 {
+		t.Fatalf("unexpected fairness stats: %+v", result.Teams)
+	}
+	if !result.Teams[0].Imbalanced {
+		t.Fatalf("expected team with a single reviewer to be imbalanced, got %+v", result.Teams[0])
+	}
+
+	resp = getJSON(t, "/stats/fairness?threshold=abc")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestE2E_AssignmentStatsCache(t *testing.T) {
+	type statsResponse struct {
+		Users []struct {
+			UserID          string `json:"user_id"`
+			AssignedPRCount int    `json:"assigned_pr_count"`
+		} `json:"users"`
+		Cached bool `json:"cached"`
+	}
+	fetch := func() statsResponse {
+		resp := getJSON(t, "/stats/assignments?team=cache")
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d", resp.StatusCode)
+		}
+		var result statsResponse
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return result
+	}
+	total := func(s statsResponse) int {
+		n := 0
+		for _, u := range s.Users {
+			n += u.AssignedPRCount
+		}
+		return n
+	}
+
+	resp := postJSON(t, "/team/add", map[string]any{
+		"team_name": "cache",
+		"members": []map[string]any{
+			{"user_id": "ca1", "username": "Cleo", "is_active": true},
+			{"user_id": "ca2", "username": "Dan", "is_active": true},
+		},
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	first := fetch()
+	if first.Cached || total(first) != 0 {
+		t.Fatalf("expected fresh empty stats, got %+v", first)
+	}
+	if second := fetch(); !second.Cached {
+		t.Fatalf("expected repeated request to be served from cache, got %+v", second)
+	}
+
+	resp = postJSON(t, "/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-9201",
+		"pull_request_name": "Cache change",
+		"author_id":         "ca1",
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	third := fetch()
+	if third.Cached || total(third) != 1 {
+		t.Fatalf("expected PR creation to invalidate cached stats, got %+v", third)
+	}
+}
+
+func TestE2E_ExportFormats(t *testing.T) {
+	resp := postJSON(t, "/team/add", map[string]any{
+		"team_name": "export",
+		"members": []map[string]any{
+			{"user_id": "ex1", "username": "Eve", "is_active": true},
+			{"user_id": "ex2", "username": "Finn", "is_active": true},
+		},
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	resp = postJSON(t, "/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-9301",
+		"pull_request_name": "Export change",
+		"author_id":         "ex1",
+	})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	resp = getJSON(t, "/stats/assignments?team=export&format=csv")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if len(records) != 3 || records[0][0] != "user_id" {
+		t.Fatalf("expected header and two user rows, got %v", records)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet,
+		baseURL+"/pullRequest/list?author_id=ex1", http.NoBody)
+	if err != nil {
+		t.Fatalf("failed to create GET request: %v", err)
+	}
+	req.Header.Set("Accept", "application/x-ndjson")
+	resp, err = apiClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /pullRequest/list failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Fatalf("expected NDJSON content type, got %q", ct)
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	var prs []map[string]any
+	for dec.More() {
+		var pr map[string]any
+		if err := dec.Decode(&pr); err != nil {
+			t.Fatalf("failed to decode NDJSON line: %v", err)
+		}
+		prs = append(prs, pr)
+	}
+	if len(prs) != 1 || prs[0]["pull_request_id"] != "pr-9301" {
+		t.Fatalf("expected exactly pr-9301, got %v", prs)
+	}
+
+	resp = getJSON(t, "/pullRequest/list?format=xml")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestE2E_Metrics(t *testing.T) {
+	resp := getJSON(t, "/stats/assignments")
+	resp.Body.Close()
+
+	resp = getJSON(t, "/metrics")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read metrics: %v", err)
+	}
+
+	wants := []string{
+		`reviewer_service_http_requests_total{method="GET",route="/stats/assignments",status="200"}`,
+		"reviewer_service_open_pull_requests ",
+	}
+	if !inProcess {
+		// pool gauges exist only with the postgres store
+		wants = append(wants, "reviewer_service_db_pool_total_conns")
+	}
+	for _, want := range wants {
+		if !strings.Contains(string(body), want) {
+			t.Fatalf("expected %q in metrics output", want)
+		}
+	}
+}
+
+func TestE2E_HealthEndpoints(t *testing.T) {
+	resp := getJSON(t, "/health/live")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	resp = getJSON(t, "/health/ready")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	var health struct {
+		Status string            `json:"status"`
+		Checks map[string]string `json:"checks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if health.Status != "ok" || health.Checks["database"] != "ok" || health.Checks["schema"] != "ok" {
+		t.Fatalf("expected all readiness checks to pass, got %+v", health)
+	}
+}
+
+// TestE2E_ConcurrentReassignAndMerge fires reassignments of every team member
+// and a merge of the same PR at once. Whatever order they are applied in,
+// no successful change may be lost and nothing may change after the merge.
+func TestE2E_ConcurrentReassignAndMerge(t *testing.T) {
+	members := []map[string]any{}
+	for i := 1; i <= 8; i++ {
+		members = append(members, map[string]any{"user_id": fmt.Sprintf("rc%d", i), "username": fmt.Sprintf("Racer%d", i), "is_active": true})
+	}
+	resp := postJSON(t, "/team/add", map[string]any{"team_name": "race", "members": members})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	resp = postJSON(t, "/pullRequest/create", map[string]any{
+		"pull_request_id": "pr-race-1", "pull_request_name": "Race", "author_id": "rc1",
+	})
+	var created struct {
+		PR struct {
+			AssignedReviewers []string `json:"assigned_reviewers"`
+		} `json:"pr"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	resp.Body.Close()
+	if len(created.PR.AssignedReviewers) != 2 {
+		t.Fatalf("expected 2 reviewers, got %v", created.PR.AssignedReviewers)
+	}
+
+	type result struct {
+		status     int
+		old        string
+		replacedBy string
+		reviewers  []string
+		err        error
+	}
+	const reassigns = 24
+	results := make(chan result, reassigns+1)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	send := func(path string, payload map[string]any, old string) {
+		defer wg.Done()
+		<-start
+		body, _ := json.Marshal(payload)
+		r, err := apiClient.Post(baseURL+path, "application/json", bytes.NewReader(body))
+		if err != nil {
+			results <- result{err: err}
+			return
+		}
+		defer r.Body.Close()
+		var out struct {
+			PR struct {
+				AssignedReviewers []string `json:"assigned_reviewers"`
+			} `json:"pr"`
+			ReplacedBy string `json:"replaced_by"`
+		}
+		if r.StatusCode == http.StatusOK {
+			err = json.NewDecoder(r.Body).Decode(&out)
+		}
+		results <- result{status: r.StatusCode, old: old, replacedBy: out.ReplacedBy, reviewers: out.PR.AssignedReviewers, err: err}
+	}
+	for i := range reassigns {
+		old := fmt.Sprintf("rc%d", 2+i%7)
+		wg.Add(1)
+		go send("/pullRequest/reassign", map[string]any{"pull_request_id": "pr-race-1", "old_reviewer_id": old}, old)
+		if i == reassigns/2 {
+			wg.Add(1)
+			go send("/pullRequest/merge", map[string]any{"pull_request_id": "pr-race-1"}, "")
+		}
+	}
+	close(start)
+	wg.Wait()
+	close(results)
+
+	// balance[u] is 1 for a user assigned at the end and 0 otherwise when
+	// every successful reassignment was applied on top of the previous one.
+	balance := map[string]int{}
+	for _, u := range created.PR.AssignedReviewers {
+		balance[u]++
+	}
+	var mergedReviewers []string
+	for res := range results {
+		switch {
+		case res.err != nil:
+			t.Fatalf("request failed: %v", res.err)
+		case res.old == "" && res.status == http.StatusOK:
+			mergedReviewers = res.reviewers
+		case res.old == "":
+			t.Fatalf("merge failed with %d", res.status)
+		case res.status == http.StatusOK:
+			balance[res.old]--
+			balance[res.replacedBy]++
+		case res.status != http.StatusConflict:
+			t.Fatalf("unexpected reassign status %d", res.status)
+		}
+	}
+
+	resp = getJSON(t, "/pullRequest/list?author_id=rc1")
+	defer resp.Body.Close()
+	var list struct {
+		PullRequests []struct {
+			Status            string   `json:"status"`
+			AssignedReviewers []string `json:"assigned_reviewers"`
+		} `json:"pull_requests"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(list.PullRequests) != 1 || list.PullRequests[0].Status != "MERGED" {
+		t.Fatalf("expected one merged PR, got %+v", list.PullRequests)
+	}
+	final := list.PullRequests[0].AssignedReviewers
+	if strings.Join(final, ",") != strings.Join(mergedReviewers, ",") {
+		t.Fatalf("reviewers changed after merge: merged with %v, now %v", mergedReviewers, final)
+	}
+
+	seen := map[string]bool{}
+	for _, u := range final {
+		if u == "rc1" || seen[u] {
+			t.Fatalf("invalid reviewer list %v", final)
+		}
+		seen[u] = true
+	}
+	for u, n := range balance {
+		want := 0
+		if seen[u] {
+			want = 1
+		}
+		if n != want {
+			t.Fatalf("lost update: %s has balance %d but assigned=%v (final %v)", u, n, seen[u], final)
+		}
+	}
+}
+
+func postJSONIfMatch(t *testing.T, path, etag string, payload any) *http.Response {
+	return postJSONWithHeader(t, path, "If-Match", etag, payload)
+}
+
+func postJSONWithHeader(t *testing.T, path, name, value string, payload any) *http.Response {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to create POST request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(name, value)
+	resp, err := apiClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST %s failed: %v", path, err)
+	}
+	return resp
+}
+
+func TestE2E_ETagPreconditions(t *testing.T) {
+	resp := postJSON(t, "/team/add", map[string]any{
+		"team_name": "etag",
+		"members": []map[string]any{
+			{"user_id": "et1", "username": "Ada", "is_active": true},
+			{"user_id": "et2", "username": "Ben", "is_active": true},
+			{"user_id": "et3", "username": "Cid", "is_active": true},
+			{"user_id": "et4", "username": "Dot", "is_active": true},
+			{"user_id": "et5", "username": "Eli", "is_active": true},
+		},
+	})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	resp = getJSON(t, "/team/get?team_name=etag")
+	resp.Body.Close()
+	teamTag := resp.Header.Get("ETag")
+	if teamTag == "" {
+		t.Fatal("expected an ETag on team read")
+	}
+
+	// Deactivating a member changes the team representation and its ETag.
+	resp = postJSON(t, "/users/setIsActive", map[string]any{"user_id": "et4", "is_active": false})
+	resp.Body.Close()
+	resp = postJSONIfMatch(t, "/team/update", teamTag, map[string]any{"team_name": "etag", "kind": "squad"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412 for a stale team ETag, got %d", resp.StatusCode)
+	}
+
+	resp = postJSON(t, "/pullRequest/create", map[string]any{
+		"pull_request_id": "pr-etag-1", "pull_request_name": "ETag", "author_id": "et1",
+	})
+	resp.Body.Close()
+	createdTag := resp.Header.Get("ETag")
+
+	resp = getJSON(t, "/pullRequest/get?pull_request_id=pr-etag-1")
+	var got struct {
+		PR struct {
+			AssignedReviewers []string `json:"assigned_reviewers"`
+		} `json:"pr"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	resp.Body.Close()
+	if resp.Header.Get("ETag") != createdTag || createdTag == "" {
+		t.Fatalf("expected read ETag %q to match create ETag %q", resp.Header.Get("ETag"), createdTag)
+	}
+
+	resp = postJSONIfMatch(t, "/pullRequest/reassign", createdTag, map[string]any{
+		"pull_request_id": "pr-etag-1", "old_reviewer_id": got.PR.AssignedReviewers[0],
+	})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	reassignedTag := resp.Header.Get("ETag")
+	if reassignedTag == createdTag {
+		t.Fatal("expected reassignment to change the ETag")
+	}
+
+	resp = postJSONIfMatch(t, "/pullRequest/merge", createdTag, map[string]any{"pull_request_id": "pr-etag-1"})
+	var errBody struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&errBody); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusPreconditionFailed || errBody.Error.Code != "PRECONDITION_FAILED" {
+		t.Fatalf("expected 412 PRECONDITION_FAILED, got %d %s", resp.StatusCode, errBody.Error.Code)
+	}
+
+	resp = postJSONIfMatch(t, "/pullRequest/merge", reassignedTag, map[string]any{"pull_request_id": "pr-etag-1"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 with a fresh ETag, got %d", resp.StatusCode)
+	}
+}
+
+func TestE2E_IdempotencyKey(t *testing.T) {
+	resp := postJSON(t, "/team/add", map[string]any{
+		"team_name": "idem",
+		"members": []map[string]any{
+			{"user_id": "id1", "username": "Ada", "is_active": true},
+			{"user_id": "id2", "username": "Ben", "is_active": true},
+			{"user_id": "id3", "username": "Cid", "is_active": true},
+			{"user_id": "id4", "username": "Dot", "is_active": true},
+		},
+	})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	resp = postJSON(t, "/pullRequest/create", map[string]any{
+		"pull_request_id": "pr-idem-1", "pull_request_name": "Retry", "author_id": "id1",
+	})
+	var created struct {
+		PR struct {
+			AssignedReviewers []string `json:"assigned_reviewers"`
+		} `json:"pr"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	resp.Body.Close()
+
+	// A retried reassignment must not pick a second replacement.
+	reassign := map[string]any{"pull_request_id": "pr-idem-1", "old_reviewer_id": created.PR.AssignedReviewers[0]}
+	var bodies [2]string
+	for i := range bodies {
+		resp = postJSONWithHeader(t, "/pullRequest/reassign", "Idempotency-Key", "e2e-reassign-1", reassign)
+		raw, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("attempt %d: expected 200, got %d: %s", i+1, resp.StatusCode, raw)
+		}
+		if replayed := resp.Header.Get("Idempotent-Replayed") == "true"; replayed != (i == 1) {
+			t.Fatalf("attempt %d: unexpected Idempotent-Replayed %q", i+1, resp.Header.Get("Idempotent-Replayed"))
+		}
+		bodies[i] = string(raw)
+	}
+	if bodies[0] != bodies[1] {
+		t.Fatalf("expected the retry to replay %s, got %s", bodies[0], bodies[1])
+	}
+
+	resp = getJSON(t, "/pullRequest/get?pull_request_id=pr-idem-1")
+	var got struct {
+		PR struct {
+			Version int64 `json:"version"`
+		} `json:"pr"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	resp.Body.Close()
+	if got.PR.Version != 2 {
+		t.Fatalf("expected one reassignment (version 2), got version %d", got.PR.Version)
+	}
+
+	resp = postJSONWithHeader(t, "/pullRequest/merge", "Idempotency-Key", "e2e-reassign-1",
+		map[string]any{"pull_request_id": "pr-idem-1"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for a reused key, got %d", resp.StatusCode)
+	}
+}
+
+func TestE2E_APITokens(t *testing.T) {
+	resp := postJSON(t, "/team/add", map[string]any{
+		"team_name": "tokens",
+		"members": []map[string]any{
+			{"user_id": "tk1", "username": "Ada", "is_active": true},
+			{"user_id": "tk2", "username": "Ben", "is_active": true},
+		},
+	})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", resp.StatusCode)
+	}
+
+	issue := func(payload map[string]any) (id, bearer string) {
+		resp := postJSON(t, "/admin/tokens/issue", payload)
+		defer resp.Body.Close()
+		var out struct {
+			Token struct {
+				TokenID string `json:"token_id"`
+			} `json:"token"`
+			Secret string `json:"secret"`
+		}
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("expected 201 issuing a token, got %d", resp.StatusCode)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return out.Token.TokenID, "Bearer " + out.Secret
+	}
+	_, reader := issue(map[string]any{"name": "e2e dashboard", "role": "read-only"})
+	botID, bot := issue(map[string]any{"name": "e2e ci", "role": "bot"})
+
+	createPR := map[string]any{"pull_request_id": "pr-token-1", "pull_request_name": "Auth", "author_id": "tk1"}
+	resp = postJSONWithHeader(t, "/pullRequest/create", "Authorization", reader, createPR)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 for a read-only token, got %d", resp.StatusCode)
+	}
+	resp = postJSONWithHeader(t, "/pullRequest/create", "Authorization", bot, createPR)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 for a bot token, got %d", resp.StatusCode)
+	}
+
+	resp = postJSON(t, "/admin/tokens/revoke", map[string]any{"token_id": botID})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	resp = postJSONWithHeader(t, "/pullRequest/merge", "Authorization", bot, map[string]any{"pull_request_id": "pr-token-1"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a revoked token, got %d", resp.StatusCode)
+	}
+}
+
+func TestE2E_AuditLog(t *testing.T) {
+	for team, users := range map[string][]string{"audit": {"au1", "au2", "au3"}, "audit-other": {"ax1"}} {
+		members := []map[string]any{}
+		for _, u := range users {
+			members = append(members, map[string]any{"user_id": u, "username": u, "is_active": true})
+		}
+		resp := postJSON(t, "/team/add", map[string]any{"team_name": team, "members": members})
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("expected 201, got %d", resp.StatusCode)
+		}
+	}
+
+	resp := postJSON(t, "/admin/tokens/issue", map[string]any{"name": "e2e audit lead", "role": "team-lead", "team_name": "audit"})
+	var issued struct {
+		Token struct {
+			TokenID string `json:"token_id"`
+		} `json:"token"`
+		Secret string `json:"secret"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&issued); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	resp.Body.Close()
+	lead := "Bearer " + issued.Secret
+
+	resp = postJSONWithHeader(t, "/users/deactivate", "Authorization", lead, map[string]any{"user_ids": []string{"au2", "au3"}})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	resp = postJSONWithHeader(t, "/users/setIsActive", "Authorization", lead, map[string]any{"user_id": "ax1", "is_active": false})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 outside the lead's team, got %d", resp.StatusCode)
+	}
+
+	type auditPage struct {
+		Entries []struct {
+			Actor  string `json:"actor"`
+			Action string `json:"action"`
+			Target string `json:"target"`
+			Result string `json:"result"`
+		} `json:"entries"`
+	}
+	list := func(query string) auditPage {
+		resp := getJSON(t, "/admin/audit?"+query)
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200 for %s, got %d", query, resp.StatusCode)
+		}
+		var page auditPage
+		if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return page
+	}
+
+	page := list("action=users.deactivate&target=au3")
+	if len(page.Entries) != 1 || page.Entries[0].Actor != "e2e audit lead" || page.Entries[0].Result != "OK" ||
+		page.Entries[0].Target != "au2,au3" {
+		t.Fatalf("expected the lead's deactivation, got %+v", page.Entries)
+	}
+	page = list("actor=" + issued.Token.TokenID + "&result=FORBIDDEN")
+	if len(page.Entries) != 1 || page.Entries[0].Action != "user.set_active" || page.Entries[0].Target != "ax1" {
+		t.Fatalf("expected the rejected activation change, got %+v", page.Entries)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, baseURL+"/admin/audit", http.NoBody)
+	if err != nil {
+		t.Fatalf("failed to create GET request: %v", err)
+	}
+	req.Header.Set("Authorization", lead)
+	resp, err = apiClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /admin/audit failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 for a team lead, got %d", resp.StatusCode)
+	}
+}
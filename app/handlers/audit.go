@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reviewer-service/app/apierror"
+	"reviewer-service/app/repository"
+	"strconv"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+func parseAuditFilter(r *http.Request) (repository.AuditFilter, error) {
+	q := r.URL.Query()
+	f := repository.AuditFilter{
+		Actor:  q.Get("actor"),
+		Action: q.Get("action"),
+		Target: q.Get("target"),
+		Result: q.Get("result"),
+		Limit:  defaultAuditLimit,
+	}
+
+	var err error
+	if f.From, err = parseTimeParam(q.Get("from")); err != nil {
+		return f, errors.New("from must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	}
+	if f.To, err = parseTimeParam(q.Get("to")); err != nil {
+		return f, errors.New("to must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	}
+	if v := q.Get("limit"); v != "" {
+		if f.Limit, err = strconv.Atoi(v); err != nil || f.Limit < 1 || f.Limit > maxAuditLimit {
+			return f, fmt.Errorf("limit must be between 1 and %d", maxAuditLimit)
+		}
+	}
+	if v := q.Get("before_id"); v != "" {
+		if f.BeforeID, err = strconv.ParseInt(v, 10, 64); err != nil || f.BeforeID < 1 {
+			return f, errors.New("before_id must be a positive integer")
+		}
+	}
+	return f, nil
+}
+
+// GetAuditLogHandler lists audit entries newest first. A full page comes with
+// next_before_id, which fetches the following page when passed as before_id.
+func (h *Handler) GetAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		apierror.Write(w, r, apierror.Validation(err.Error()))
+		return
+	}
+
+	entries, err := h.svc.ListAudit(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, "GetAuditLogHandler", err)
+		return
+	}
+
+	resp := map[string]any{"entries": entries}
+	if len(entries) == filter.Limit {
+		resp["next_before_id"] = entries[len(entries)-1].ID
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseAuditFilter(t *testing.T) {
+	req := httptest.NewRequest("GET", "/admin/audit?actor=ci&target=u1&from=2025-10-01&limit=5&before_id=42", nil)
+	f, err := parseAuditFilter(req)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if f.Actor != "ci" || f.Target != "u1" || f.From == nil || f.To != nil || f.Limit != 5 || f.BeforeID != 42 {
+		t.Fatalf("unexpected filter %+v", f)
+	}
+
+	if f, _ := parseAuditFilter(httptest.NewRequest("GET", "/admin/audit", nil)); f.Limit != defaultAuditLimit {
+		t.Fatalf("expected the default limit, got %d", f.Limit)
+	}
+	for _, query := range []string{"limit=0", "limit=1001", "limit=x", "before_id=-1", "from=yesterday"} {
+		if _, err := parseAuditFilter(httptest.NewRequest("GET", "/admin/audit?"+query, nil)); err == nil {
+			t.Errorf("%s: expected an error", query)
+		}
+	}
+}
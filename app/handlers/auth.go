@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"reviewer-service/app/apierror"
+	"reviewer-service/app/auth"
+	"reviewer-service/app/models"
+	"reviewer-service/app/service"
+	"slices"
+	"strings"
+)
+
+// Route role sets. Admins may call everything; team leads are further limited
+// to their own team by the service.
+var (
+	anyRole   = []auth.Role{auth.RoleAdmin, auth.RoleTeamLead, auth.RoleBot, auth.RoleReadOnly}
+	prWriters = []auth.Role{auth.RoleAdmin, auth.RoleTeamLead, auth.RoleBot}
+	teamLeads = []auth.Role{auth.RoleAdmin, auth.RoleTeamLead}
+	adminOnly = []auth.Role{auth.RoleAdmin}
+)
+
+// bootstrapPrincipal is the caller authenticated by the configured bootstrap
+// token.
+var bootstrapPrincipal = auth.Principal{TokenID: "bootstrap", Name: "bootstrap", Role: auth.RoleAdmin}
+
+// authenticate resolves the bearer token of the request and stores the
+// caller in the request context. Requests without a valid token get 401.
+func (h *Handler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !h.authEnabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		secret, ok := bearerToken(r)
+		if !ok {
+			unauthorized(w, r, "missing bearer token")
+			return
+		}
+
+		var p auth.Principal
+		if h.bootstrapHash != "" &&
+			subtle.ConstantTimeCompare([]byte(auth.HashToken(secret)), []byte(h.bootstrapHash)) == 1 {
+			p = bootstrapPrincipal
+		} else {
+			var err error
+			if p, err = h.svc.Authenticate(r.Context(), secret); err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				writeError(w, r, "authenticate", err)
+				return
+			}
+		}
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), p)))
+	})
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, message string) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	apierror.Write(w, r, &apierror.Error{Status: http.StatusUnauthorized, Code: service.CodeUnauthorized, Message: message})
+}
+
+// allow lets only callers with one of roles through to next.
+func (h *Handler) allow(roles []auth.Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !h.authEnabled {
+			next(w, r)
+			return
+		}
+		p, ok := auth.FromContext(r.Context())
+		if !ok {
+			unauthorized(w, r, "missing bearer token")
+			return
+		}
+		if !slices.Contains(roles, p.Role) {
+			apierror.Write(w, r, apierror.Forbidden(fmt.Sprintf("role %s may not call this endpoint", p.Role)))
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (h *Handler) IssueTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.IssueTokenRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	token, secret, err := h.svc.IssueToken(r.Context(), req)
+	if err != nil {
+		writeError(w, r, "IssueTokenHandler", err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"token": token, "secret": secret})
+}
+
+func (h *Handler) RevokeTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.RevokeTokenRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	token, err := h.svc.RevokeToken(r.Context(), req.TokenID)
+	if err != nil {
+		writeError(w, r, "RevokeTokenHandler", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"token": token})
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reviewer-service/app/apierror"
+	"reviewer-service/app/config"
+	"reviewer-service/app/repository/memory"
+	"reviewer-service/app/service"
+	"strings"
+	"testing"
+)
+
+const testBootstrapToken = "bootstrap-token-for-tests"
+
+func authRequest(t *testing.T, h http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) apierror.Code {
+	t.Helper()
+	var body apierror.Response
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode error: %v", err)
+	}
+	return body.Error.Code
+}
+
+func issueToken(t *testing.T, h http.Handler, body string) (id, secret string) {
+	t.Helper()
+	rec := authRequest(t, h, "POST", "/admin/tokens/issue", testBootstrapToken, body)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201 issuing %s, got %d: %s", body, rec.Code, rec.Body)
+	}
+	var resp struct {
+		Token struct {
+			TokenID string `json:"token_id"`
+		} `json:"token"`
+		Secret string `json:"secret"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode token: %v", err)
+	}
+	return resp.Token.TokenID, resp.Secret
+}
+
+func TestAuthRoles(t *testing.T) {
+	cfg := config.Default()
+	cfg.Auth.BootstrapToken = testBootstrapToken
+	router := New(memory.New(), cfg).Router(QueryTimeouts{})
+	admin := testBootstrapToken
+
+	for _, team := range []string{
+		`{"team_name":"backend","members":[{"user_id":"b1","username":"Ann","is_active":true},{"user_id":"b2","username":"Bob","is_active":true}]}`,
+		`{"team_name":"frontend","members":[{"user_id":"f1","username":"Cat","is_active":true},{"user_id":"f2","username":"Dan","is_active":true}]}`,
+	} {
+		if rec := authRequest(t, router, "POST", "/team/add", admin, team); rec.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body)
+		}
+	}
+
+	rec := authRequest(t, router, "GET", "/team/get?team_name=backend", "", "")
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+		t.Fatalf("expected 401 with a challenge, got %d", rec.Code)
+	}
+	if rec := authRequest(t, router, "GET", "/team/get?team_name=backend", "rvs_unknown", ""); errorCode(t, rec) != service.CodeUnauthorized {
+		t.Fatal("expected an unknown token to be rejected")
+	}
+	if rec := authRequest(t, router, "GET", "/health/live", "", ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected health checks to stay open, got %d", rec.Code)
+	}
+	if rec := authRequest(t, router, "GET", "/metrics", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected metrics to require a token, got %d", rec.Code)
+	}
+	if rec := authRequest(t, router, "GET", "/nope", "", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown route, got %d", rec.Code)
+	}
+
+	if rec := authRequest(t, router, "POST", "/admin/tokens/issue", admin, `{"name":"lead","role":"team-lead"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected a team-lead token without a team to be rejected, got %d", rec.Code)
+	}
+	_, reader := issueToken(t, router, `{"name":"dashboard","role":"read-only"}`)
+	_, bot := issueToken(t, router, `{"name":"ci","role":"bot"}`)
+	leadID, lead := issueToken(t, router, `{"name":"backend lead","role":"team-lead","team_name":"backend"}`)
+
+	cases := []struct {
+		name   string
+		token  string
+		path   string
+		body   string
+		status int
+	}{
+		{"reader reads", reader, "/team/get?team_name=backend", "", http.StatusOK},
+		{"reader reads metrics", reader, "/metrics", "", http.StatusOK},
+		{"reader cannot write", reader, "/pullRequest/create", `{"pull_request_id":"pr-0","pull_request_name":"X","author_id":"b1"}`, http.StatusForbidden},
+		{"bot creates PR", bot, "/pullRequest/create", `{"pull_request_id":"pr-1","pull_request_name":"X","author_id":"f1"}`, http.StatusCreated},
+		{"bot cannot manage users", bot, "/users/setIsActive", `{"user_id":"b1","is_active":false}`, http.StatusForbidden},
+		{"lead manages own team", lead, "/users/setIsActive", `{"user_id":"b2","is_active":true}`, http.StatusOK},
+		{"lead cannot touch other team", lead, "/users/setIsActive", `{"user_id":"f2","is_active":false}`, http.StatusForbidden},
+		{"lead cannot deactivate other team", lead, "/users/deactivate", `{"user_ids":["b2","f2"]}`, http.StatusForbidden},
+		{"lead cannot update other team", lead, "/team/update", `{"team_name":"frontend","kind":"squad"}`, http.StatusForbidden},
+		{"lead updates own team", lead, "/team/update", `{"team_name":"backend","kind":"squad"}`, http.StatusOK},
+		{"lead cannot reparent own team", lead, "/team/update", `{"team_name":"backend","parent_team":"frontend"}`, http.StatusForbidden},
+		{"lead cannot add fallback teams", lead, "/team/update", `{"team_name":"backend","fallback_teams":["frontend"]}`, http.StatusForbidden},
+		{"lead cannot merge other team's PR", lead, "/pullRequest/merge", `{"pull_request_id":"pr-1"}`, http.StatusForbidden},
+		{"lead cannot create teams", lead, "/team/add", `{"team_name":"ops","members":[]}`, http.StatusForbidden},
+		{"lead cannot issue tokens", lead, "/admin/tokens/issue", `{"name":"x","role":"admin"}`, http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		method := "POST"
+		if tc.body == "" {
+			method = "GET"
+		}
+		if rec := authRequest(t, router, method, tc.path, tc.token, tc.body); rec.Code != tc.status {
+			t.Errorf("%s: expected %d, got %d: %s", tc.name, tc.status, rec.Code, rec.Body)
+		}
+	}
+
+	if rec := authRequest(t, router, "POST", "/admin/tokens/revoke", admin, `{"token_id":"`+leadID+`"}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := authRequest(t, router, "GET", "/team/get?team_name=backend", lead, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected a revoked token to be rejected, got %d", rec.Code)
+	}
+}
+
+func TestIdempotencyKeysArePerToken(t *testing.T) {
+	cfg := config.Default()
+	cfg.Auth.BootstrapToken = testBootstrapToken
+	router := New(memory.New(), cfg).Router(QueryTimeouts{})
+	_, bot := issueToken(t, router, `{"name":"ci","role":"bot"}`)
+
+	team := `{"team_name":"backend","members":[{"user_id":"b1","username":"Ann","is_active":true}]}`
+	if rec := authRequest(t, router, "POST", "/team/add", testBootstrapToken, team); rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+
+	pr := `{"pull_request_id":"pr-1","pull_request_name":"X","author_id":"b1"}`
+	send := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/pullRequest/create", strings.NewReader(pr))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set(idempotencyKeyHeader, "same-key")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := send(bot); rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+	if rec := send(testBootstrapToken); rec.Code != http.StatusConflict {
+		t.Fatalf("expected another token's request to run and hit PR_EXISTS, got %d", rec.Code)
+	}
+}
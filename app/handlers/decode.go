@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reviewer-service/app/apierror"
+	"reviewer-service/app/service"
+	"reviewer-service/app/validation"
+)
+
+// validator is implemented by the request models.
+type validator interface {
+	Validate() error
+}
+
+// decodeJSON reads a single JSON object from the body into dst and validates
+// it. Unknown fields, trailing data and bodies over maxBodyBytes are
+// rejected. On failure the error response is already written and false is
+// returned.
+func (h *Handler) decodeJSON(w http.ResponseWriter, r *http.Request, dst validator) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, h.maxBodyBytes))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(dst)
+	if err == nil {
+		if _, extra := dec.Token(); !errors.Is(extra, io.EOF) {
+			err = errors.New("body must contain a single JSON object")
+		}
+	}
+	if err != nil {
+		apierror.Write(w, r, decodeError(err))
+		return false
+	}
+
+	if err := dst.Validate(); err != nil {
+		var fields validation.Errors
+		if errors.As(err, &fields) {
+			apierror.Write(w, r, apierror.InvalidFields(fields))
+		} else {
+			apierror.Write(w, r, apierror.Validation(err.Error()))
+		}
+		return false
+	}
+	return true
+}
+
+func decodeError(err error) *apierror.Error {
+	var tooLarge *http.MaxBytesError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &tooLarge):
+		return &apierror.Error{
+			Status:  http.StatusRequestEntityTooLarge,
+			Code:    service.CodeBadRequest,
+			Message: fmt.Sprintf("request body must not exceed %d bytes", tooLarge.Limit),
+		}
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return apierror.InvalidFields(map[string]string{typeErr.Field: "must be " + jsonType(typeErr.Type.Kind().String())})
+	}
+	if field, ok := unknownField(err); ok {
+		return apierror.InvalidFields(map[string]string{field: "unknown field"})
+	}
+	return apierror.BadRequest("invalid request body")
+}
+
+// unknownField extracts the field name from the error DisallowUnknownFields
+// produces; encoding/json has no typed error for it.
+func unknownField(err error) (string, bool) {
+	var field string
+	if _, scanErr := fmt.Sscanf(err.Error(), "json: unknown field %q", &field); scanErr != nil {
+		return "", false
+	}
+	return field, true
+}
+
+func jsonType(kind string) string {
+	switch kind {
+	case "string":
+		return "a string"
+	case "bool":
+		return "a boolean"
+	case "slice", "array":
+		return "an array"
+	case "struct", "map", "ptr":
+		return "an object"
+	default:
+		return "a number"
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reviewer-service/app/apierror"
+	"reviewer-service/app/config"
+	"reviewer-service/app/repository/memory"
+	"reviewer-service/app/service"
+	"strings"
+	"testing"
+)
+
+func TestRequestValidation(t *testing.T) {
+	cfg := config.Default()
+	cfg.HTTP.MaxBodyBytes = 256
+	cfg.Auth.Enabled = false
+	router := New(memory.New(), cfg).Router(QueryTimeouts{})
+
+	cases := []struct {
+		name    string
+		path    string
+		body    string
+		status  int
+		details map[string]string
+	}{
+		{
+			name:    "empty fields",
+			path:    "/pullRequest/create",
+			body:    `{"pull_request_id":"","pull_request_name":"  ","author_id":""}`,
+			status:  http.StatusBadRequest,
+			details: map[string]string{"pull_request_id": "is required", "pull_request_name": "must not be blank", "author_id": "is required"},
+		},
+		{
+			name:    "unknown field",
+			path:    "/pullRequest/merge",
+			body:    `{"pull_request_id":"pr-1","force":true}`,
+			status:  http.StatusBadRequest,
+			details: map[string]string{"force": "unknown field"},
+		},
+		{
+			name:    "bad ID and blank username",
+			path:    "/team/add",
+			body:    `{"team_name":"back end","members":[{"user_id":"u1","username":""}]}`,
+			status:  http.StatusBadRequest,
+			details: map[string]string{"team_name": "may only contain letters, digits, '.', '-' and '_'", "members[0].username": "must not be blank"},
+		},
+		{
+			name:   "trailing data",
+			path:   "/pullRequest/merge",
+			body:   `{"pull_request_id":"pr-1"} {}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "too large",
+			path:   "/pullRequest/merge",
+			body:   `{"pull_request_id":"` + strings.Repeat("a", 300) + `"}`,
+			status: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest("POST", c.path, strings.NewReader(c.body)))
+			if rec.Code != c.status {
+				t.Fatalf("expected %d, got %d: %s", c.status, rec.Code, rec.Body)
+			}
+			var body apierror.Response
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode body: %v", err)
+			}
+			for field, msg := range c.details {
+				if body.Error.Details[field] != msg {
+					t.Fatalf("expected %s: %q, got details %v", field, msg, body.Error.Details)
+				}
+			}
+			if c.details != nil && body.Error.Code != service.CodeValidation {
+				t.Fatalf("expected VALIDATION_ERROR, got %s", body.Error.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"reviewer-service/app/apierror"
+	"reviewer-service/app/service"
+)
+
+// writeJSON sends v with the given status. Encoding errors can only be
+// logged: the status line is already out.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("failed to encode response: %v", err)
+	}
+}
+
+// writeError maps err to an error response. Domain errors keep their code,
+// a hit request deadline is reported as TIMEOUT (504) and anything else as
+// INTERNAL. Nothing useful can be sent to a client that has gone away, so
+// cancellations are only logged.
+func writeError(w http.ResponseWriter, r *http.Request, op string, err error) {
+	id := apierror.RequestID(r.Context())
+
+	var apiErr *apierror.Error
+	var domainErr *service.Error
+	switch {
+	case errors.As(err, &apiErr):
+		apierror.Write(w, r, apiErr)
+	case isTimeout(err):
+		log.Printf("[%s] %s: deadline exceeded: %v", id, op, err)
+		apierror.Write(w, r, apierror.Timeout())
+	case errors.Is(err, context.Canceled):
+		log.Printf("[%s] %s: request canceled: %v", id, op, err)
+	case errors.As(err, &domainErr):
+		if domainErr.Err != nil {
+			log.Printf("[%s] %s: %v", id, op, err)
+		}
+		apierror.Write(w, r, apierror.FromService(domainErr))
+	default:
+		log.Printf("[%s] %s: %v", id, op, err)
+		apierror.Write(w, r, apierror.Internal())
+	}
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"reviewer-service/app/apierror"
+	"reviewer-service/app/service"
+	"strconv"
+	"strings"
+)
+
+// setETag sends a resource version as a strong entity tag.
+func setETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// parseIfMatch turns the If-Match header into a precondition. A missing
+// header and "*" impose none. Weak and foreign tags can never match, since
+// If-Match uses strong comparison, so a header listing only those fails the
+// precondition.
+func parseIfMatch(r *http.Request) (service.Precondition, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	cond := service.Precondition{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		weak := strings.HasPrefix(tag, "W/")
+		tag = strings.TrimPrefix(tag, "W/")
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			return nil, apierror.Validation("If-Match must be * or a list of quoted entity tags")
+		}
+		version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+		if err == nil && !weak {
+			cond = append(cond, version)
+		}
+	}
+	return cond, nil
+}
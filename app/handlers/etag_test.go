@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"reviewer-service/app/service"
+	"slices"
+	"testing"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	cases := []struct {
+		header string
+		want   service.Precondition
+	}{
+		{"", nil},
+		{"*", nil},
+		{`"3"`, service.Precondition{3}},
+		{`"3", "5"`, service.Precondition{3, 5}},
+		{`W/"3"`, service.Precondition{}},
+		{`"abc"`, service.Precondition{}},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest("POST", "/pullRequest/merge", nil)
+		req.Header.Set("If-Match", c.header)
+		got, err := parseIfMatch(req)
+		if err != nil {
+			t.Fatalf("%q: unexpected error %v", c.header, err)
+		}
+		if (got == nil) != (c.want == nil) || !slices.Equal(got, c.want) {
+			t.Fatalf("%q: expected %v, got %v", c.header, c.want, got)
+		}
+	}
+
+	req := httptest.NewRequest("POST", "/pullRequest/merge", nil)
+	req.Header.Set("If-Match", "3")
+	if _, err := parseIfMatch(req); err == nil {
+		t.Fatal("expected an unquoted tag to be rejected")
+	}
+}
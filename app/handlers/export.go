@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Response formats for endpoints that support export. CSV and NDJSON are
+// written row by row while the query result is being read.
+const (
+	formatJSON   = "json"
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+)
+
+var formatMediaTypes = map[string]string{
+	"application/json":     formatJSON,
+	"text/csv":             formatCSV,
+	"application/x-ndjson": formatNDJSON,
+	"application/ndjson":   formatNDJSON,
+}
+
+// exportFlushEvery is how many rows are buffered before flushing to the client.
+const exportFlushEvery = 100
+
+// negotiateFormat picks the response format from the format query parameter
+// or, when it is absent, from the first supported media type in Accept.
+func negotiateFormat(r *http.Request) (string, error) {
+	if f := r.URL.Query().Get("format"); f != "" {
+		switch f {
+		case formatJSON, formatCSV, formatNDJSON:
+			return f, nil
+		}
+		return "", errors.New("format must be one of json, csv, ndjson")
+	}
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if f, ok := formatMediaTypes[mediaType]; ok {
+			return f, nil
+		}
+	}
+	return formatJSON, nil
+}
+
+// exportWriter streams rows as CSV (with a header line) or as one JSON object
+// per line.
+type exportWriter struct {
+	format  string
+	csv     *csv.Writer
+	enc     *json.Encoder
+	flusher http.Flusher
+	rows    int
+}
+
+func newExportWriter(w http.ResponseWriter, format, filename string, header []string) (*exportWriter, error) {
+	e := &exportWriter{format: format}
+	e.flusher, _ = w.(http.Flusher)
+
+	switch format {
+	case formatCSV:
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".csv"))
+		e.csv = csv.NewWriter(w)
+		if err := e.csv.Write(header); err != nil {
+			return nil, err
+		}
+	case formatNDJSON:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		e.enc = json.NewEncoder(w)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+	return e, nil
+}
+
+// write emits record for CSV or value for NDJSON. CSV cells are escaped
+// against formula injection.
+func (e *exportWriter) write(record []string, value any) error {
+	var err error
+	if e.csv != nil {
+		cells := make([]string, len(record))
+		for i, cell := range record {
+			cells[i] = csvCell(cell)
+		}
+		err = e.csv.Write(cells)
+	} else {
+		err = e.enc.Encode(value)
+	}
+	if err != nil {
+		return err
+	}
+
+	e.rows++
+	if e.rows%exportFlushEvery == 0 {
+		e.flush()
+	}
+	return nil
+}
+
+// csvCell prefixes a cell that a spreadsheet would run as a formula with a
+// quote, so that user-supplied names are shown as text.
+func csvCell(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+func (e *exportWriter) flush() {
+	if e.csv != nil {
+		e.csv.Flush()
+	}
+	if e.flusher != nil {
+		e.flusher.Flush()
+	}
+}
+
+func (e *exportWriter) close() error {
+	e.flush()
+	if e.csv != nil {
+		return e.csv.Error()
+	}
+	return nil
+}
+
+// streamExport writes the rows produced by each as CSV or NDJSON. The response
+// is started with the first row, so an error before that is still reported as
+// a server error; later errors can only abort the stream.
+func streamExport[T any](w http.ResponseWriter, r *http.Request, format, filename string, header []string, op string,
+	each func(fn func(T) error) error, record func(T) []string) {
+	var out *exportWriter
+	start := func() error {
+		var err error
+		out, err = newExportWriter(w, format, filename, header)
+		return err
+	}
+
+	err := each(func(v T) error {
+		if out == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return out.write(record(v), v)
+	})
+	if err == nil && out == nil {
+		err = start()
+	}
+	switch {
+	case err != nil && out == nil:
+		writeError(w, r, op+" failed", err)
+		return
+	case err != nil:
+		log.Printf("%s aborted: %v", op, err)
+		return
+	}
+	if err := out.close(); err != nil {
+		log.Printf("%s aborted: %v", op, err)
+	}
+}
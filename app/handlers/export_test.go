@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	cases := []struct {
+		url, accept, want string
+	}{
+		{"/stats/assignments", "", formatJSON},
+		{"/stats/assignments", "text/csv", formatCSV},
+		{"/stats/assignments", "text/html, application/x-ndjson;q=0.9", formatNDJSON},
+		{"/stats/assignments?format=csv", "application/json", formatCSV},
+		{"/stats/assignments", "*/*", formatJSON},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", c.url, nil)
+		if c.accept != "" {
+			r.Header.Set("Accept", c.accept)
+		}
+		got, err := negotiateFormat(r)
+		if err != nil || got != c.want {
+			t.Fatalf("%s with Accept %q: expected %s, got %s (err=%v)", c.url, c.accept, c.want, got, err)
+		}
+	}
+
+	if _, err := negotiateFormat(httptest.NewRequest("GET", "/stats/assignments?format=xml", nil)); err == nil {
+		t.Fatal("expected unknown format to be rejected")
+	}
+}
+
+func TestExportWriterCSV(t *testing.T) {
+	rec := httptest.NewRecorder()
+	out, err := newExportWriter(rec, formatCSV, "users", []string{"user_id", "username"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := out.write([]string{"u1", "Alice, Jr."}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := out.write([]string{"u2", `=HYPERLINK("http://x")`}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := out.write([]string{"u3", "+cmd|' /C calc'!A0"}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := out.close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := rec.Header().Get("Content-Type"); got != "text/csv; charset=utf-8" {
+		t.Fatalf("unexpected content type %q", got)
+	}
+	want := "user_id,username\nu1,\"Alice, Jr.\"\n" +
+		"u2,\"'=HYPERLINK(\"\"http://x\"\")\"\n" +
+		"u3,'+cmd|' /C calc'!A0\n"
+	if rec.Body.String() != want {
+		t.Fatalf("expected %q, got %q", want, rec.Body.String())
+	}
+}
+
+func TestExportWriterNDJSON(t *testing.T) {
+	rec := httptest.NewRecorder()
+	out, err := newExportWriter(rec, formatNDJSON, "users", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, id := range []string{"u1", "u2"} {
+		if err := out.write(nil, map[string]string{"user_id": id}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := out.close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := "{\"user_id\":\"u1\"}\n{\"user_id\":\"u2\"}\n"; rec.Body.String() != want {
+		t.Fatalf("expected %q, got %q", want, rec.Body.String())
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"reviewer-service/app/apierror"
+	"strconv"
+)
+
+func parseGiniThreshold(v string) (float64, bool) {
+	t, err := strconv.ParseFloat(v, 64)
+	if err != nil || t < 0 || t > 1 {
+		return 0, false
+	}
+	return t, true
+}
+
+// giniThreshold reads the imbalance threshold from the request, falling back
+// to the configured stats.fairness_gini_threshold.
+func (h *Handler) giniThreshold(r *http.Request) (float64, bool) {
+	if v := r.URL.Query().Get("threshold"); v != "" {
+		return parseGiniThreshold(v)
+	}
+	return h.defaultGiniThreshold, true
+}
+
+// GetFairnessStatsHandler reports how evenly reviews are spread inside each
+// team; see service.Fairness.
+func (h *Handler) GetFairnessStatsHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseDateRange(w, r)
+	if !ok {
+		return
+	}
+	threshold, ok := h.giniThreshold(r)
+	if !ok {
+		apierror.Write(w, r, apierror.Validation("threshold must be a number between 0 and 1"))
+		return
+	}
+
+	resp, err := h.svc.Fairness(r.Context(), from, to, r.URL.Query().Get("team"), threshold)
+	if err != nil {
+		writeError(w, r, "GetFairnessStatsHandler", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGiniThreshold(t *testing.T) {
+	h := &Handler{defaultGiniThreshold: 0.5}
+
+	if got, ok := h.giniThreshold(httptest.NewRequest("GET", "/stats/fairness", nil)); !ok || got != 0.5 {
+		t.Fatalf("expected configured threshold, got %v (ok=%v)", got, ok)
+	}
+	if got, ok := h.giniThreshold(httptest.NewRequest("GET", "/stats/fairness?threshold=0.2", nil)); !ok || got != 0.2 {
+		t.Fatalf("expected threshold from query, got %v (ok=%v)", got, ok)
+	}
+	if _, ok := h.giniThreshold(httptest.NewRequest("GET", "/stats/fairness?threshold=2", nil)); ok {
+		t.Fatal("expected out of range threshold to be rejected")
+	}
+}
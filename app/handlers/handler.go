@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+	"reviewer-service/app/apierror"
+	"reviewer-service/app/auth"
+	"reviewer-service/app/config"
+	"reviewer-service/app/metrics"
+	"reviewer-service/app/repository"
+	"reviewer-service/app/service"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Handler serves the HTTP API: it decodes requests, calls the service and
+// maps its errors to responses.
+type Handler struct {
+	svc *service.Service
+	// pool is set when the store is backed by a connection pool.
+	pool poolStatter
+	// defaultGiniThreshold is used when the threshold query parameter is absent.
+	defaultGiniThreshold float64
+	maxBodyBytes         int64
+	idempotencyKeys      repository.IdempotencyRepo
+	idempotencyTTL       time.Duration
+	authEnabled          bool
+	// bootstrapHash is the hash of the configured bootstrap token, if any.
+	bootstrapHash string
+	// draining is set once shutdown starts.
+	draining atomic.Bool
+}
+
+func New(store repository.Store, cfg config.Config) *Handler {
+	h := &Handler{
+		svc:                  service.New(store, cfg.Stats.CacheTTL),
+		defaultGiniThreshold: cfg.Stats.FairnessGiniThreshold,
+		maxBodyBytes:         int64(cfg.HTTP.MaxBodyBytes),
+		idempotencyKeys:      store.Idempotency(),
+		idempotencyTTL:       cfg.HTTP.IdempotencyTTL,
+		authEnabled:          cfg.Auth.Enabled,
+	}
+	if cfg.Auth.BootstrapToken != "" {
+		h.bootstrapHash = auth.HashToken(cfg.Auth.BootstrapToken)
+	}
+	h.pool, _ = store.(poolStatter)
+	return h
+}
+
+// Router registers every endpoint behind the request ID, metrics and query
+// timeout middlewares. Health checks are open; every other route
+// requires a token with one of the roles it is registered with, and its POST
+// requests honor Idempotency-Key. Unknown paths and methods get JSON errors too.
+func (h *Handler) Router(timeouts QueryTimeouts) *mux.Router {
+	r := mux.NewRouter()
+	r.NotFoundHandler = apierror.RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apierror.Write(w, r, apierror.NotFound("route not found"))
+	}))
+	r.MethodNotAllowedHandler = apierror.RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apierror.Write(w, r, &apierror.Error{Status: http.StatusMethodNotAllowed, Code: service.CodeBadRequest, Message: "method not allowed"})
+	}))
+	r.Use(apierror.RequestIDMiddleware)
+	r.Use(metrics.Middleware)
+	r.Use(timeouts.Middleware)
+
+	// Health checks
+	r.HandleFunc("/health/live", LiveHandler).Methods("GET")
+	r.HandleFunc("/health/ready", h.ReadyHandler).Methods("GET")
+
+	api := r.NewRoute().Subrouter()
+	api.Use(h.authenticate)
+
+	// Metrics name teams and their review load, so they need a token too.
+	api.HandleFunc("/metrics", h.allow(anyRole, h.MetricsHandler)).Methods("GET")
+
+	// Admin endpoints. They skip idempotency so that issued secrets are never
+	// stored.
+	adminRouter := api.PathPrefix("/admin").Subrouter()
+	adminRouter.HandleFunc("/tokens/issue", h.allow(adminOnly, h.IssueTokenHandler)).Methods("POST")
+	adminRouter.HandleFunc("/tokens/revoke", h.allow(adminOnly, h.RevokeTokenHandler)).Methods("POST")
+	adminRouter.HandleFunc("/audit", h.allow(adminOnly, h.GetAuditLogHandler)).Methods("GET")
+
+	api = api.NewRoute().Subrouter()
+	api.Use(h.idempotency)
+
+	// Team endpoints
+	teamRouter := api.PathPrefix("/team").Subrouter()
+	teamRouter.HandleFunc("/add", h.allow(adminOnly, h.CreateTeamHandler)).Methods("POST")
+	teamRouter.HandleFunc("/get", h.allow(anyRole, h.GetTeamHandler)).Methods("GET")
+	teamRouter.HandleFunc("/update", h.allow(teamLeads, h.UpdateTeamHandler)).Methods("POST")
+	teamRouter.HandleFunc("/tree", h.allow(anyRole, h.GetTeamTreeHandler)).Methods("GET")
+
+	// User endpoints
+	userRouter := api.PathPrefix("/users").Subrouter()
+	userRouter.HandleFunc("/setIsActive", h.allow(teamLeads, h.SetUserActiveHandler)).Methods("POST")
+	userRouter.HandleFunc("/setSeniority", h.allow(teamLeads, h.SetUserSeniorityHandler)).Methods("POST")
+	userRouter.HandleFunc("/getReview", h.allow(anyRole, h.GetUserPRsHandler)).Methods("GET")
+	userRouter.HandleFunc("/deactivate", h.allow(teamLeads, h.ProcessUserDeactivationHandler)).Methods("POST")
+
+	// PullRequest endpoints
+	prRouter := api.PathPrefix("/pullRequest").Subrouter()
+	prRouter.HandleFunc("/create", h.allow(prWriters, h.CreatePRHandler)).Methods("POST")
+	prRouter.HandleFunc("/get", h.allow(anyRole, h.GetPRHandler)).Methods("GET")
+	prRouter.HandleFunc("/merge", h.allow(prWriters, h.MergePRHandler)).Methods("POST")
+	prRouter.HandleFunc("/reassign", h.allow(prWriters, h.ReassignPRHandler)).Methods("POST")
+	prRouter.HandleFunc("/explainAssignment", h.allow(anyRole, h.ExplainAssignmentHandler)).Methods("POST")
+	prRouter.HandleFunc("/submitReview", h.allow(prWriters, h.SubmitReviewHandler)).Methods("POST")
+	prRouter.HandleFunc("/list", h.allow(anyRole, h.ListPRsHandler)).Methods("GET")
+
+	// Stats endpoints
+	api.HandleFunc("/stats/assignments", h.allow(anyRole, h.GetAssignmentStatsHandler)).Methods("GET")
+	api.HandleFunc("/stats/assignments/tree", h.allow(anyRole, h.GetTeamAssignmentStatsTreeHandler)).Methods("GET")
+	api.HandleFunc("/stats/simulate", h.allow(anyRole, h.SimulateAssignmentsHandler)).Methods("POST")
+	api.HandleFunc("/stats/mergeTime", h.allow(anyRole, h.GetMergeTimeStatsHandler)).Methods("GET")
+	api.HandleFunc("/stats/reviewLatency", h.allow(anyRole, h.GetReviewLatencyStatsHandler)).Methods("GET")
+	api.HandleFunc("/stats/trends", h.allow(anyRole, h.GetWeeklyTrendsHandler)).Methods("GET")
+	api.HandleFunc("/stats/fairness", h.allow(anyRole, h.GetFairnessStatsHandler)).Methods("GET")
+
+	return r
+}
+
+// DefaultQueryTimeouts gives the stats and PR export routes their longer
+// deadline.
+func DefaultQueryTimeouts(cfg config.HTTPConfig) QueryTimeouts {
+	return QueryTimeouts{
+		Default: cfg.QueryTimeout,
+		ByPrefix: map[string]time.Duration{
+			"/stats/":           cfg.StatsQueryTimeout,
+			"/pullRequest/list": cfg.StatsQueryTimeout,
+		},
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"reviewer-service/app/models"
+	"time"
+)
+
+// readinessTimeout bounds the database checks done by the readiness probe.
+const readinessTimeout = 2 * time.Second
+
+const (
+	healthOK       = "ok"
+	healthNotReady = "not_ready"
+	// healthUnavailable reports a failed check without its error.
+	healthUnavailable = "unavailable"
+)
+
+// SetDraining marks the instance as shutting down so that readiness fails
+// while in-flight requests are still being served.
+func (h *Handler) SetDraining() {
+	h.draining.Store(true)
+}
+
+func writeHealth(w http.ResponseWriter, status int, resp models.HealthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("failed to encode health response: %v", err)
+	}
+}
+
+// LiveHandler reports that the process is up and serving HTTP. It does not
+// touch the database, so a database outage does not get the instance
+// restarted.
+func LiveHandler(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, http.StatusOK, models.HealthResponse{Status: healthOK})
+}
+
+// ReadyHandler reports whether the instance can serve traffic: it must not be
+// shutting down, and the store must answer a ping and have the expected
+// schema version applied.
+func (h *Handler) ReadyHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	if h.draining.Load() {
+		writeHealth(w, http.StatusServiceUnavailable, models.HealthResponse{
+			Status: healthNotReady,
+			Checks: map[string]string{"shutdown": "draining"},
+		})
+		return
+	}
+
+	resp := models.HealthResponse{Status: healthOK, Checks: map[string]string{}}
+	status := http.StatusOK
+	// The probe is unauthenticated, so errors that may name the database
+	// host or user only go to the log.
+	fail := func(check string, err error) {
+		log.Printf("readiness check %s failed: %v", check, err)
+		resp.Checks[check] = healthUnavailable
+		resp.Status = healthNotReady
+		status = http.StatusServiceUnavailable
+	}
+
+	if err := h.svc.Ping(ctx); err != nil {
+		fail("database", err)
+		resp.Checks["schema"] = "skipped"
+		writeHealth(w, status, resp)
+		return
+	}
+	resp.Checks["database"] = healthOK
+
+	if err := h.svc.CheckSchema(ctx); err != nil {
+		fail("schema", err)
+	} else {
+		resp.Checks["schema"] = healthOK
+	}
+	writeHealth(w, status, resp)
+}
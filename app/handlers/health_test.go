@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reviewer-service/app/config"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"reviewer-service/app/repository/memory"
+	"strings"
+	"testing"
+)
+
+func TestReadyHandlerFailsWhileDraining(t *testing.T) {
+	h := New(memory.New(), config.Default())
+	rec := httptest.NewRecorder()
+	h.ReadyHandler(rec, httptest.NewRequest("GET", "/health/ready", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 before draining, got %d", rec.Code)
+	}
+
+	h.SetDraining()
+
+	rec = httptest.NewRecorder()
+	h.ReadyHandler(rec, httptest.NewRequest("GET", "/health/ready", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 while draining, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	LiveHandler(rec, httptest.NewRequest("GET", "/health/live", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected liveness to stay 200 while draining, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	New(memory.New(), config.Default()).ReadyHandler(rec, httptest.NewRequest("GET", "/health/ready", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected another handler to stay ready, got %d", rec.Code)
+	}
+}
+
+type unreachableStore struct{ repository.Store }
+
+func (unreachableStore) Ping(ctx context.Context) error {
+	return errors.New("dial tcp db.internal:5432 user=reviewer: connection refused")
+}
+
+func TestReadyHandlerHidesErrors(t *testing.T) {
+	h := New(unreachableStore{memory.New()}, config.Default())
+	rec := httptest.NewRecorder()
+	h.ReadyHandler(rec, httptest.NewRequest("GET", "/health/ready", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "db.internal") {
+		t.Fatalf("expected the error to stay out of the body, got %s", rec.Body)
+	}
+	var resp models.HealthResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if resp.Checks["database"] != healthUnavailable {
+		t.Fatalf("expected database to be %q, got %+v", healthUnavailable, resp.Checks)
+	}
+}
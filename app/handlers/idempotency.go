@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"reviewer-service/app/apierror"
+	"reviewer-service/app/auth"
+	"reviewer-service/app/repository"
+	"time"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	maxIdempotencyKeyLen = 255
+	// idempotencySaveTimeout bounds storing the outcome of a request, which
+	// happens even when the client has already gone away.
+	idempotencySaveTimeout = 5 * time.Second
+)
+
+// idempotency makes POST requests that carry an Idempotency-Key safe to
+// retry. The first request with a key runs normally and its response is
+// stored; a retry with the same key and body gets that response back
+// (marked with Idempotent-Replayed) without running again. The replayed body
+// is kept verbatim, so an error in it carries the request_id of the first
+// request while X-Request-ID names the retry. Reusing a key for
+// a different request fails with 422, and a retry that arrives while the
+// first request is still running fails with 409. Server errors are not
+// stored, so the retry of a failed request runs again. Keys of different API
+// tokens never clash.
+func (h *Handler) idempotency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !validIdempotencyKey(key) {
+			apierror.Write(w, r, apierror.Validation("Idempotency-Key must be 1 to 255 printable ASCII characters"))
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBodyBytes))
+		if err != nil {
+			apierror.Write(w, r, decodeError(err))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		hash := requestHash(r, body)
+		// Keys are per token, so that one client cannot collide with or
+		// read the stored responses of another.
+		if p, ok := auth.FromContext(r.Context()); ok {
+			key = p.TokenID + ":" + key
+		}
+
+		rec, claimed, err := h.idempotencyKeys.Claim(r.Context(), key, hash, time.Now().Add(-h.idempotencyTTL))
+		if err != nil {
+			writeError(w, r, "Failed to claim idempotency key", err)
+			return
+		}
+		if !claimed {
+			replayIdempotent(w, r, rec, hash)
+			return
+		}
+
+		resp := &bufferedResponse{header: w.Header()}
+		defer func() {
+			// The outcome is saved even if the client has gone away, so that
+			// its retry finds it.
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), idempotencySaveTimeout)
+			defer cancel()
+			if p := recover(); p != nil {
+				h.releaseIdempotencyKey(ctx, r, key)
+				panic(p)
+			}
+			if resp.status == 0 || resp.status >= http.StatusInternalServerError {
+				h.releaseIdempotencyKey(ctx, r, key)
+			} else {
+				rec.Status, rec.Body = resp.status, resp.body.Bytes()
+				rec.ContentType, rec.ETag = w.Header().Get("Content-Type"), w.Header().Get("ETag")
+				if err := h.idempotencyKeys.Complete(ctx, rec); err != nil {
+					log.Printf("[%s] failed to store response for idempotency key: %v", apierror.RequestID(r.Context()), err)
+				}
+			}
+			resp.flush(w)
+		}()
+		next.ServeHTTP(resp, r)
+	})
+}
+
+func (h *Handler) releaseIdempotencyKey(ctx context.Context, r *http.Request, key string) {
+	if err := h.idempotencyKeys.Release(ctx, key); err != nil {
+		log.Printf("[%s] failed to release idempotency key: %v", apierror.RequestID(r.Context()), err)
+	}
+}
+
+func replayIdempotent(w http.ResponseWriter, r *http.Request, rec repository.IdempotencyRecord, hash string) {
+	switch {
+	case rec.RequestHash != hash:
+		apierror.Write(w, r, &apierror.Error{
+			Status:  http.StatusUnprocessableEntity,
+			Code:    apierror.CodeIdempotencyKeyReused,
+			Message: "Idempotency-Key was already used for a different request",
+		})
+	case !rec.Done:
+		apierror.Write(w, r, &apierror.Error{
+			Status:  http.StatusConflict,
+			Code:    apierror.CodeIdempotencyKeyInUse,
+			Message: "a request with this Idempotency-Key is still being processed",
+		})
+	default:
+		if rec.ContentType != "" {
+			w.Header().Set("Content-Type", rec.ContentType)
+		}
+		if rec.ETag != "" {
+			w.Header().Set("ETag", rec.ETag)
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		log.Printf("[%s] replaying stored %d response for idempotency key", apierror.RequestID(r.Context()), rec.Status)
+		w.WriteHeader(rec.Status)
+		if _, err := w.Write(rec.Body); err != nil {
+			log.Printf("[%s] failed to replay response: %v", apierror.RequestID(r.Context()), err)
+		}
+	}
+}
+
+// requestHash identifies a request by method, path with query and exact body.
+func requestHash(r *http.Request, body []byte) string {
+	sum := sha256.New()
+	sum.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	sum.Write(body)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLen {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// bufferedResponse holds a response until it has been stored. Headers go
+// straight to the real writer's header map.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponse) flush(w http.ResponseWriter) {
+	if b.status == 0 {
+		return
+	}
+	w.WriteHeader(b.status)
+	if _, err := w.Write(b.body.Bytes()); err != nil {
+		log.Printf("failed to write response: %v", err)
+	}
+}
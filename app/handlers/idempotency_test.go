@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reviewer-service/app/apierror"
+	"reviewer-service/app/config"
+	"reviewer-service/app/repository/memory"
+	"strings"
+	"testing"
+)
+
+func postWithKey(h http.Handler, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set(idempotencyKeyHeader, key)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotencyKeyReplaysResponse(t *testing.T) {
+	cfg := config.Default()
+	cfg.Auth.Enabled = false
+	router := New(memory.New(), cfg).Router(QueryTimeouts{})
+	team := `{"team_name":"backend","members":[{"user_id":"u1","username":"Alice","is_active":true},` +
+		`{"user_id":"u2","username":"Bob","is_active":true}]}`
+	if rec := postWithKey(router, "/team/add", "team-1", team); rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+
+	pr := `{"pull_request_id":"pr-1","pull_request_name":"Fix","author_id":"u1"}`
+	first := postWithKey(router, "/pullRequest/create", "create-1", pr)
+	retry := postWithKey(router, "/pullRequest/create", "create-1", pr)
+	if first.Code != http.StatusCreated || retry.Code != http.StatusCreated {
+		t.Fatalf("expected both attempts to return 201, got %d and %d", first.Code, retry.Code)
+	}
+	if retry.Body.String() != first.Body.String() || retry.Header().Get("ETag") != first.Header().Get("ETag") {
+		t.Fatalf("expected the original response, got %s", retry.Body)
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" || first.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatal("expected only the retry to be marked as replayed")
+	}
+
+	rec := postWithKey(router, "/pullRequest/create", "create-1", strings.Replace(pr, "Fix", "Other", 1))
+	var body apierror.Response
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if rec.Code != http.StatusUnprocessableEntity || body.Error.Code != apierror.CodeIdempotencyKeyReused {
+		t.Fatalf("expected 422 IDEMPOTENCY_KEY_REUSED, got %d %s", rec.Code, body.Error.Code)
+	}
+
+	if rec := postWithKey(router, "/pullRequest/create", "create-2", pr); rec.Code != http.StatusConflict {
+		t.Fatalf("expected a new key to run the request again and hit PR_EXISTS, got %d", rec.Code)
+	}
+}
+
+func TestIdempotencyReplayKeepsOriginalRequestID(t *testing.T) {
+	cfg := config.Default()
+	cfg.Auth.Enabled = false
+	router := New(memory.New(), cfg).Router(QueryTimeouts{})
+
+	merge := `{"pull_request_id":"pr-missing"}`
+	first := postWithKey(router, "/pullRequest/merge", "merge-1", merge)
+	retry := postWithKey(router, "/pullRequest/merge", "merge-1", merge)
+	if first.Code != http.StatusNotFound || retry.Code != http.StatusNotFound {
+		t.Fatalf("expected both attempts to return 404, got %d and %d", first.Code, retry.Code)
+	}
+
+	var body apierror.Response
+	if err := json.NewDecoder(retry.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	firstID, retryID := first.Header().Get(apierror.RequestIDHeader), retry.Header().Get(apierror.RequestIDHeader)
+	if body.Error.RequestID != firstID || retryID == firstID {
+		t.Fatalf("expected the replayed body to carry %q and the header a new ID, got %q and %q", firstID, body.Error.RequestID, retryID)
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatal("expected the retry to be marked as replayed")
+	}
+}
+
+func TestIdempotencyKeyReleasedOnServerError(t *testing.T) {
+	h := New(memory.New(), config.Default())
+	calls := 0
+	handler := h.idempotency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			apierror.Write(w, r, apierror.Internal())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]int{"calls": calls})
+	}))
+
+	if rec := postWithKey(handler, "/users/deactivate", "k", `{}`); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", rec.Code)
+	}
+	if rec := postWithKey(handler, "/users/deactivate", "k", `{}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected the retry to run again, got %d", rec.Code)
+	}
+	if rec := postWithKey(handler, "/users/deactivate", "k", `{}`); rec.Code != http.StatusOK || calls != 2 {
+		t.Fatalf("expected the stored success to be replayed, got %d after %d calls", rec.Code, calls)
+	}
+	if rec := postWithKey(handler, "/users/deactivate", "bad key", `{}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected an invalid key to be rejected, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"reviewer-service/app/apierror"
+	"time"
+)
+
+// parseDateRange reads the optional from/to query parameters shared by the
+// latency endpoints.
+func parseDateRange(w http.ResponseWriter, r *http.Request) (from, to *time.Time, ok bool) {
+	var err error
+	if from, err = parseTimeParam(r.URL.Query().Get("from")); err != nil {
+		apierror.Write(w, r, apierror.Validation("from must be an RFC 3339 timestamp or a YYYY-MM-DD date"))
+		return nil, nil, false
+	}
+	if to, err = parseTimeParam(r.URL.Query().Get("to")); err != nil {
+		apierror.Write(w, r, apierror.Validation("to must be an RFC 3339 timestamp or a YYYY-MM-DD date"))
+		return nil, nil, false
+	}
+	return from, to, true
+}
+
+func (h *Handler) GetMergeTimeStatsHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseDateRange(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := h.svc.MergeTimes(r.Context(), from, to)
+	if err != nil {
+		writeError(w, r, "Failed to fetch merge time stats", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func (h *Handler) GetReviewLatencyStatsHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseDateRange(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := h.svc.ReviewLatency(r.Context(), from, to)
+	if err != nil {
+		writeError(w, r, "Failed to fetch review latency stats", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func (h *Handler) GetWeeklyTrendsHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseDateRange(w, r)
+	if !ok {
+		return
+	}
+
+	trends, err := h.svc.WeeklyTrends(r.Context(), from, to)
+	if err != nil {
+		writeError(w, r, "Failed to fetch weekly trends", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, trends)
+}
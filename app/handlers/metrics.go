@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"reviewer-service/app/metrics"
+	"sort"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// poolStatter is implemented by stores backed by a pgx pool.
+type poolStatter interface {
+	Stat() *pgxpool.Stat
+}
+
+// MetricsHandler exposes request, connection pool and domain metrics in the
+// Prometheus text format. Pool metrics are written only for stores backed by
+// a connection pool; domain gauges are skipped when the store is unavailable
+// so that the scrape itself still succeeds.
+func (h *Handler) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", metrics.ContentType)
+	mw := metrics.NewWriter(w)
+
+	metrics.WriteHTTP(mw)
+
+	if h.pool != nil {
+		writePoolStats(mw, h.pool.Stat())
+	}
+
+	dm, err := h.svc.OpenReviews(r.Context())
+	if err != nil {
+		log.Printf("MetricsHandler: %v", err)
+	} else {
+		mw.Gauge("reviewer_service_open_pull_requests", "Pull requests in OPEN status.", float64(dm.OpenPRs))
+		mw.Gauge("reviewer_service_open_pull_requests_without_reviewers", "OPEN pull requests with no assigned reviewer.", float64(dm.OpenPRsNoReviewers))
+
+		const name = "reviewer_service_team_open_review_load"
+		mw.Header(name, "gauge", "Open review assignments held by members of each team.")
+		for _, team := range sortedKeys(dm.OpenReviewLoadByTeam) {
+			mw.Sample(name, float64(dm.OpenReviewLoadByTeam[team]), metrics.Label{Name: "team", Value: team})
+		}
+	}
+
+	if err := mw.Err(); err != nil {
+		log.Printf("MetricsHandler: failed to write metrics: %v", err)
+	}
+}
+
+func writePoolStats(mw *metrics.Writer, s *pgxpool.Stat) {
+	mw.Gauge("reviewer_service_db_pool_total_conns", "Connections currently open in the pool.", float64(s.TotalConns()))
+	mw.Gauge("reviewer_service_db_pool_acquired_conns", "Connections currently acquired.", float64(s.AcquiredConns()))
+	mw.Gauge("reviewer_service_db_pool_idle_conns", "Idle connections in the pool.", float64(s.IdleConns()))
+	mw.Gauge("reviewer_service_db_pool_max_conns", "Maximum size of the pool.", float64(s.MaxConns()))
+	mw.Counter("reviewer_service_db_pool_acquires_total", "Successful connection acquisitions.", float64(s.AcquireCount()))
+	mw.Counter("reviewer_service_db_pool_empty_acquires_total", "Acquisitions that had to wait for a connection.", float64(s.EmptyAcquireCount()))
+	mw.Counter("reviewer_service_db_pool_canceled_acquires_total", "Acquisitions canceled by their context.", float64(s.CanceledAcquireCount()))
+	mw.Counter("reviewer_service_db_pool_acquire_duration_seconds_total", "Total time spent acquiring connections.", s.AcquireDuration().Seconds())
+	mw.Counter("reviewer_service_db_pool_new_conns_total", "Connections opened by the pool.", float64(s.NewConnsCount()))
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
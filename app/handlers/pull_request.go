@@ -1,221 +1,220 @@
 package handlers
 
 import (
-	"context"
-	"encoding/json"
-	"log"
+	"errors"
 	"net/http"
-	"reviewer-service/app/db"
+	"reviewer-service/app/apierror"
 	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"strings"
 	"time"
 )
 
-func CreatePRHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreatePRHandler(w http.ResponseWriter, r *http.Request) {
 	var req models.CreatePRRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
-	ctx := context.Background()
-
-	var teamName string
-	err := db.Pool.QueryRow(ctx, "SELECT team_name FROM users WHERE user_id=$1", req.AuthorID).Scan(&teamName)
+	res, err := h.svc.CreatePR(r.Context(), req)
 	if err != nil {
-		http.Error(w, `{"error":{"code":"NOT_FOUND","message":"author or team not found"}}`, http.StatusNotFound)
+		writeError(w, r, "CreatePRHandler", err)
 		return
 	}
 
-	rows, err := db.Pool.Query(ctx, `
-		SELECT user_id FROM users
-		WHERE team_name=$1 AND is_active=true AND user_id<>$2
-	`, teamName, req.AuthorID)
-	if err != nil {
-		http.Error(w, "internal server error", http.StatusInternalServerError)
-		return
+	response := map[string]any{
+		"pr":                   res.PR,
+		"cross_team":           len(res.CrossTeam) > 0,
+		"cross_team_reviewers": res.CrossTeam,
+		"learning_reviewers":   res.Learning,
+		"composition_warnings": res.Warnings,
 	}
-	defer rows.Close()
-
-	candidates := []string{}
-	for rows.Next() {
-		var u string
-		if err = rows.Scan(&u); err == nil {
-			candidates = append(candidates, u)
-		}
+	if isVerbose(r) {
+		response["explanation"] = res.Explanation
 	}
 
-	assigned := []string{}
-	for i := 0; i < 2 && len(candidates) > 0; i++ {
-		assigned = append(assigned, candidates[0])
-		candidates = candidates[1:]
-	}
+	setETag(w, res.PR.Version)
+	writeJSON(w, http.StatusCreated, response)
+}
 
-	_, err = db.Pool.Exec(ctx, `
-		INSERT INTO pull_requests(pull_request_id, pull_request_name, author_id, status, assigned_reviewers)
-		VALUES($1,$2,$3,'OPEN',$4)
-	`, req.PullRequestID, req.PullRequestName, req.AuthorID, assigned)
-	if err != nil {
-		http.Error(w, `{"error":{"code":"PR_EXISTS","message":"PR id already exists"}}`, http.StatusConflict)
+func (h *Handler) GetPRHandler(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		apierror.Write(w, r, apierror.Validation("pull_request_id query param required"))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	err = json.NewEncoder(w).Encode(map[string]any{
-		"pr": models.PullRequest{
-			PullRequestID:     req.PullRequestID,
-			PullRequestName:   req.PullRequestName,
-			AuthorID:          req.AuthorID,
-			Status:            "OPEN",
-			AssignedReviewers: assigned,
-		},
-	})
+	pr, err := h.svc.GetPR(r.Context(), prID)
 	if err != nil {
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		writeError(w, r, "GetPRHandler", err)
 		return
 	}
+
+	setETag(w, pr.Version)
+	writeJSON(w, http.StatusOK, map[string]any{"pr": pr})
 }
 
-func MergePRHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) MergePRHandler(w http.ResponseWriter, r *http.Request) {
 	var req models.MergePRRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
-	ctx := context.Background()
-
-	var status string
-	err := db.Pool.QueryRow(ctx, "SELECT status FROM pull_requests WHERE pull_request_id=$1", req.PullRequestID).Scan(&status)
+	cond, err := parseIfMatch(r)
 	if err != nil {
-		http.Error(w, `{"error":{"code":"NOT_FOUND","message":"PR not found"}}`, http.StatusNotFound)
+		writeError(w, r, "MergePRHandler", err)
 		return
 	}
 
-	if status != "MERGED" {
-		_, _ = db.Pool.Exec(ctx, `
-			UPDATE pull_requests
-			SET status='MERGED', merged_at=NOW()
-			WHERE pull_request_id=$1
-		`, req.PullRequestID)
+	pr, err := h.svc.MergePR(r.Context(), req.PullRequestID, cond)
+	if err != nil {
+		writeError(w, r, "MergePRHandler", err)
+		return
 	}
+	pr.CreatedAt = nil
+	setETag(w, pr.Version)
 
-	var pr models.PullRequest
-	var reviewers []string
-	var mergedAt *time.Time
+	writeJSON(w, http.StatusOK, map[string]any{"pr": pr})
+}
 
-	err = db.Pool.QueryRow(ctx, `
-		SELECT pull_request_id, pull_request_name, author_id, status, assigned_reviewers, merged_at
-		FROM pull_requests
-		WHERE pull_request_id=$1
-	`, req.PullRequestID).Scan(
-		&pr.PullRequestID,
-		&pr.PullRequestName,
-		&pr.AuthorID,
-		&pr.Status,
-		&reviewers,
-		&mergedAt,
-	)
+func (h *Handler) ReassignPRHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.ReassignPRRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
 
+	cond, err := parseIfMatch(r)
 	if err != nil {
-		log.Printf("MergePRHandler: failed to fetch PR %s: %v", req.PullRequestID, err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		writeError(w, r, "ReassignPRHandler", err)
 		return
 	}
 
-	pr.AssignedReviewers = reviewers
-	if mergedAt != nil {
-		pr.MergedAt = mergedAt
+	res, err := h.svc.ReassignPR(r.Context(), req.PullRequestID, req.OldReviewerID, cond)
+	if err != nil {
+		writeError(w, r, "ReassignPRHandler", err)
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(map[string]any{"pr": pr}); err != nil {
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
-		return
+	response := map[string]any{
+		"pr":                   res.PR,
+		"replaced_by":          res.ReplacedBy,
+		"cross_team":           len(res.CrossTeam) > 0,
+		"composition_warnings": res.Warnings,
 	}
+	if isVerbose(r) {
+		response["explanation"] = res.Explanation
+	}
+
+	setETag(w, res.PR.Version)
+	writeJSON(w, http.StatusOK, response)
 }
 
-func ReassignPRHandler(w http.ResponseWriter, r *http.Request) {
-	var req models.ReassignPRRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+func (h *Handler) SubmitReviewHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.SubmitReviewRequest
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
-	ctx := context.Background()
-
-	var status string
-	var assigned []string
-	err := db.Pool.QueryRow(ctx, `
-		SELECT status, assigned_reviewers FROM pull_requests WHERE pull_request_id=$1
-	`, req.PullRequestID).Scan(&status, &assigned)
+	review, err := h.svc.SubmitReview(r.Context(), req)
 	if err != nil {
-		http.Error(w, `{"error":{"code":"NOT_FOUND","message":"PR not found"}}`, http.StatusNotFound)
+		writeError(w, r, "SubmitReviewHandler", err)
 		return
 	}
 
-	if status == "MERGED" {
-		http.Error(w, `{"error":{"code":"PR_MERGED","message":"cannot reassign on merged PR"}}`, http.StatusConflict)
+	writeJSON(w, http.StatusOK, map[string]any{"review": review})
+}
+
+func isVerbose(r *http.Request) bool {
+	v := r.URL.Query().Get("verbose")
+	return v == "true" || v == "1"
+}
+
+// ExplainAssignmentHandler runs reviewer selection without writing anything;
+// see service.ExplainAssignment.
+func (h *Handler) ExplainAssignmentHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.ExplainAssignmentRequest
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
-	found := false
-	for i, uid := range assigned {
-		if uid != req.OldReviewerID {
-			continue
-		}
-		found = true
+	explanation, err := h.svc.ExplainAssignment(r.Context(), req)
+	if err != nil {
+		writeError(w, r, "ExplainAssignmentHandler", err)
+		return
+	}
 
-		var teamName string
-		err = db.Pool.QueryRow(ctx, "SELECT team_name FROM users WHERE user_id=$1", req.OldReviewerID).Scan(&teamName)
-		if err != nil {
-			http.Error(w, "internal server error", http.StatusInternalServerError)
-			return
-		}
+	writeJSON(w, http.StatusOK, map[string]any{"explanation": explanation})
+}
 
-		rows, _ := db.Pool.Query(ctx, `
-			SELECT user_id FROM users WHERE team_name=$1 AND is_active=true AND user_id<>$2
-		`, teamName, req.OldReviewerID)
-		candidates := []string{}
-		for rows.Next() {
-			var u string
-			if err := rows.Scan(&u); err != nil {
-				http.Error(w, "internal server error", http.StatusInternalServerError)
-				rows.Close()
-				return
-			}
-			candidates = append(candidates, u)
-		}
-		if err := rows.Err(); err != nil {
-			http.Error(w, "internal server error", http.StatusInternalServerError)
-			return
-		}
-		rows.Close()
+func parsePullRequestListFilter(r *http.Request) (repository.PullRequestFilter, error) {
+	q := r.URL.Query()
+	f := repository.PullRequestFilter{AuthorID: q.Get("author_id"), Team: q.Get("team")}
 
-		if len(candidates) == 0 {
-			http.Error(w, `{"error":{"code":"NO_CANDIDATE","message":"no active replacement candidate in team"}}`, http.StatusConflict)
-			return
+	status := q.Get("status")
+	if status == "" {
+		status = "all"
+	}
+	prStatus, ok := prStatusFilters[status]
+	if !ok {
+		return f, errors.New("status must be one of open, merged, all")
+	}
+	f.Status = prStatus
+
+	var err error
+	if f.From, err = parseTimeParam(q.Get("from")); err != nil {
+		return f, errors.New("from must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	}
+	if f.To, err = parseTimeParam(q.Get("to")); err != nil {
+		return f, errors.New("to must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	}
+	return f, nil
+}
+
+func pullRequestRecord(pr models.PullRequest) []string {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
 		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	return []string{
+		pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status,
+		strings.Join(pr.AssignedReviewers, ";"), formatTime(pr.CreatedAt), formatTime(pr.MergedAt),
+	}
+}
 
-		newReviewer := candidates[0]
-		assigned[i] = newReviewer
+// ListPRsHandler returns PR history. JSON is buffered; CSV and NDJSON are
+// streamed as the rows are read.
+func (h *Handler) ListPRsHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parsePullRequestListFilter(r)
+	if err != nil {
+		apierror.Write(w, r, apierror.Validation(err.Error()))
+		return
+	}
+	format, err := negotiateFormat(r)
+	if err != nil {
+		apierror.Write(w, r, apierror.Validation(err.Error()))
+		return
+	}
 
-		_, _ = db.Pool.Exec(ctx, `
-			UPDATE pull_requests SET assigned_reviewers=$1 WHERE pull_request_id=$2
-		`, assigned, req.PullRequestID)
+	list := func(fn func(models.PullRequest) error) error {
+		return h.svc.ListPRs(r.Context(), filter, fn)
+	}
 
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(map[string]any{
-			"pr":          models.PullRequest{PullRequestID: req.PullRequestID, AssignedReviewers: assigned},
-			"replaced_by": newReviewer,
+	if format == formatJSON {
+		prs := []models.PullRequest{}
+		if err := list(func(pr models.PullRequest) error {
+			prs = append(prs, pr)
+			return nil
 		}); err != nil {
-			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+			writeError(w, r, "Failed to list PRs", err)
 			return
 		}
-	}
-
-	if !found {
-		http.Error(w, `{"error":{"code":"NOT_ASSIGNED","message":"reviewer is not assigned to this PR"}}`, http.StatusConflict)
+		writeJSON(w, http.StatusOK, map[string]any{"pull_requests": prs})
 		return
 	}
+
+	streamExport(w, r, format, "pull_requests", []string{
+		"pull_request_id", "pull_request_name", "author_id", "status", "assigned_reviewers", "created_at", "merged_at",
+	}, "PR export", list, pullRequestRecord)
 }
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+	"reviewer-service/app/models"
+)
+
+// SimulateAssignmentsHandler replays past PRs with a selection strategy; see
+// service.Simulate.
+func (h *Handler) SimulateAssignmentsHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.SimulationRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	res, err := h.svc.Simulate(r.Context(), req)
+	if err != nil {
+		writeError(w, r, "SimulateAssignmentsHandler", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
@@ -1,54 +1,112 @@
 package handlers
 
 import (
-	"context"
-	"encoding/json"
-	"log"
+	"errors"
 	"net/http"
-	"reviewer-service/app/db"
+	"reviewer-service/app/apierror"
 	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"strconv"
+	"time"
 )
 
-func GetAssignmentStatsHandler(w http.ResponseWriter, _ *http.Request) {
-	ctx := context.Background()
-
-	query := `
-		SELECT 
-			u.user_id, 
-			u.username, 
-			COUNT(reviewers.reviewer_id) AS assigned_pr_count
-		FROM 
-			users u
-		LEFT JOIN 
-			(SELECT unnest(assigned_reviewers) AS reviewer_id FROM pull_requests) AS reviewers 
-            ON u.user_id = reviewers.reviewer_id
-		GROUP BY 
-			u.user_id, u.username
-		ORDER BY 
-			assigned_pr_count DESC, u.username ASC;
-    `
-
-	rows, err := db.Pool.Query(ctx, query)
+var prStatusFilters = map[string]string{"all": "", "open": "OPEN", "merged": "MERGED"}
+
+func parseAssignmentStatsFilter(r *http.Request) (repository.AssignmentStatsFilter, error) {
+	q := r.URL.Query()
+	f := repository.AssignmentStatsFilter{Team: q.Get("team")}
+
+	status := q.Get("status")
+	if status == "" {
+		status = "all"
+	}
+	prStatus, ok := prStatusFilters[status]
+	if !ok {
+		return f, errors.New("status must be one of open, merged, all")
+	}
+	f.Status = prStatus
+
+	var err error
+	if f.From, err = parseTimeParam(q.Get("from")); err != nil {
+		return f, errors.New("from must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	}
+	if f.To, err = parseTimeParam(q.Get("to")); err != nil {
+		return f, errors.New("to must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	}
+
+	if v := q.Get("active_only"); v != "" {
+		if f.ActiveOnly, err = strconv.ParseBool(v); err != nil {
+			return f, errors.New("active_only must be a boolean")
+		}
+	}
+	return f, nil
+}
+
+func parseTimeParam(v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		t, err = time.Parse(time.DateOnly, v)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (h *Handler) GetAssignmentStatsHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAssignmentStatsFilter(r)
 	if err != nil {
-		log.Printf("Failed to fetch assignment stats: %v", err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		apierror.Write(w, r, apierror.Validation(err.Error()))
+		return
+	}
+	format, err := negotiateFormat(r)
+	if err != nil {
+		apierror.Write(w, r, apierror.Validation(err.Error()))
+		return
+	}
+	if format != formatJSON {
+		h.exportAssignmentStats(w, r, filter, format)
 		return
 	}
-	defer rows.Close()
 
-	var stats []models.UserAssignmentStats
-	for rows.Next() {
-		var s models.UserAssignmentStats
-		if err := rows.Scan(&s.UserID, &s.Username, &s.AssignedPRCount); err != nil {
-			log.Printf("Failed to scan stats row: %v", err)
-			continue
-		}
-		stats = append(stats, s)
+	resp, err := h.svc.AssignmentStats(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, "GetAssignmentStatsHandler", err)
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(stats); err != nil {
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// exportAssignmentStats streams the per-user rows, bypassing the stats cache.
+// Team totals are not included.
+func (h *Handler) exportAssignmentStats(w http.ResponseWriter, r *http.Request, filter repository.AssignmentStatsFilter, format string) {
+	streamExport(w, r, format, "assignment_stats",
+		[]string{"user_id", "username", "team_name", "is_active", "assigned_pr_count"},
+		"Assignment stats export",
+		func(fn func(models.UserAssignmentStats) error) error {
+			return h.svc.EachAssignmentStat(r.Context(), filter, fn)
+		},
+		func(s models.UserAssignmentStats) []string {
+			return []string{s.UserID, s.Username, s.TeamName, strconv.FormatBool(s.IsActive), strconv.Itoa(s.AssignedPRCount)}
+		})
+}
+
+func (h *Handler) GetTeamAssignmentStatsTreeHandler(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		apierror.Write(w, r, apierror.Validation("team_name query param required"))
 		return
 	}
+
+	stats, err := h.svc.TeamAssignmentStatsTree(r.Context(), teamName)
+	if err != nil {
+		writeError(w, r, "GetTeamAssignmentStatsTreeHandler", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
 }
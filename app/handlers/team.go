@@ -1,91 +1,78 @@
 package handlers
 
 import (
-	"context"
-	"encoding/json"
-	"log"
 	"net/http"
-	"reviewer-service/app/db"
+	"reviewer-service/app/apierror"
 	"reviewer-service/app/models"
-
-	"github.com/jackc/pgx/v5/pgconn"
 )
 
-func CreateTeamHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateTeamHandler(w http.ResponseWriter, r *http.Request) {
 	var team models.Team
-	if err := json.NewDecoder(r.Body).Decode(&team); err != nil {
-		http.Error(w, "invalid request body", http.StatusBadRequest)
+	if !h.decodeJSON(w, r, &team) {
 		return
 	}
 
-	_, err := db.Pool.Exec(context.Background(), `
-		INSERT INTO teams(team_name) VALUES($1)
-	`, team.TeamName)
+	team, err := h.svc.CreateTeam(r.Context(), team)
 	if err != nil {
-		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" { // уникальный ключ
-			http.Error(w, `{"error":{"code":"TEAM_EXISTS","message":"team_name already exists"}}`, http.StatusBadRequest)
-			return
-		}
-		log.Printf("Failed to create team: %v", err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		writeError(w, r, "CreateTeamHandler", err)
 		return
 	}
 
-	for _, member := range team.Members {
-		_, _ = db.Pool.Exec(context.Background(), `
-			INSERT INTO users(user_id, username, team_name, is_active)
-			VALUES($1,$2,$3,$4)
-			ON CONFLICT (user_id) DO UPDATE SET username=EXCLUDED.username, team_name=EXCLUDED.team_name, is_active=EXCLUDED.is_active
-		`, member.UserID, member.Username, team.TeamName, member.IsActive)
+	setETag(w, team.Version)
+	writeJSON(w, http.StatusCreated, map[string]models.Team{"team": team})
+}
+
+func (h *Handler) GetTeamHandler(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		apierror.Write(w, r, apierror.Validation("team_name query param required"))
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(map[string]models.Team{"team": team}); err != nil {
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+	team, err := h.svc.GetTeam(r.Context(), teamName)
+	if err != nil {
+		writeError(w, r, "Failed to load team "+teamName, err)
 		return
 	}
+
+	setETag(w, team.Version)
+	writeJSON(w, http.StatusOK, team)
 }
 
-func GetTeamHandler(w http.ResponseWriter, r *http.Request) {
-	teamName := r.URL.Query().Get("team_name")
-	if teamName == "" {
-		http.Error(w, "team_name query param required", http.StatusBadRequest)
+func (h *Handler) UpdateTeamHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.UpdateTeamRequest
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
-	var exists string
-	err := db.Pool.QueryRow(context.Background(), "SELECT team_name FROM teams WHERE team_name=$1", teamName).Scan(&exists)
+	cond, err := parseIfMatch(r)
 	if err != nil {
-		http.Error(w, `{"error":{"code":"NOT_FOUND","message":"team not found"}}`, http.StatusNotFound)
+		writeError(w, r, "UpdateTeamHandler", err)
 		return
 	}
 
-	rows, err := db.Pool.Query(context.Background(), `
-		SELECT user_id, username, is_active FROM users WHERE team_name=$1
-	`, teamName)
+	team, err := h.svc.UpdateTeam(r.Context(), req, cond)
 	if err != nil {
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		writeError(w, r, "Failed to update team "+req.TeamName, err)
 		return
 	}
-	defer rows.Close()
 
-	members := []models.TeamMember{}
-	for rows.Next() {
-		var m models.TeamMember
-		if err := rows.Scan(&m.UserID, &m.Username, &m.IsActive); err != nil {
-			continue
-		}
-		members = append(members, m)
+	setETag(w, team.Version)
+	writeJSON(w, http.StatusOK, map[string]models.Team{"team": team})
+}
+
+func (h *Handler) GetTeamTreeHandler(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		apierror.Write(w, r, apierror.Validation("team_name query param required"))
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(models.Team{
-		TeamName: teamName,
-		Members:  members,
-	}); err != nil {
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+	tree, err := h.svc.TeamTree(r.Context(), teamName)
+	if err != nil {
+		writeError(w, r, "GetTeamTreeHandler", err)
 		return
 	}
+
+	writeJSON(w, http.StatusOK, tree)
 }
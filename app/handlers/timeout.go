@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// QueryTimeouts bounds the database work of a request by its mux route
+// template: the longest matching prefix in ByPrefix wins, otherwise Default
+// applies; zero means no deadline. Handlers must derive their query contexts
+// from r.Context() for the deadline (and client disconnects) to cancel the SQL.
+type QueryTimeouts struct {
+	Default  time.Duration
+	ByPrefix map[string]time.Duration
+}
+
+func (t QueryTimeouts) forRoute(route string) time.Duration {
+	d, longest := t.Default, -1
+	for prefix, pd := range t.ByPrefix {
+		if strings.HasPrefix(route, prefix) && len(prefix) > longest {
+			d, longest = pd, len(prefix)
+		}
+	}
+	return d
+}
+
+func (t QueryTimeouts) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.URL.Path
+		if cur := mux.CurrentRoute(r); cur != nil {
+			if tpl, err := cur.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+
+		d := t.forRoute(route)
+		if d <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), d)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func isTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || pgconn.Timeout(err)
+}
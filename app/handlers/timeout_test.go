@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reviewer-service/app/apierror"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestQueryTimeoutsPerRoute(t *testing.T) {
+	timeouts := QueryTimeouts{
+		Default:  time.Second,
+		ByPrefix: map[string]time.Duration{"/stats/": time.Minute, "/stats/fast": 0},
+	}
+
+	var remaining time.Duration
+	var hasDeadline bool
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var deadline time.Time
+		deadline, hasDeadline = r.Context().Deadline()
+		remaining = time.Until(deadline)
+	}
+
+	r := mux.NewRouter()
+	r.Use(timeouts.Middleware)
+	r.HandleFunc("/team/get", handler)
+	r.HandleFunc("/stats/assignments", handler)
+	r.HandleFunc("/stats/fast", handler)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/team/get", nil))
+	if !hasDeadline || remaining > time.Second {
+		t.Fatalf("expected default deadline of 1s, got %v (deadline=%v)", remaining, hasDeadline)
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/stats/assignments", nil))
+	if !hasDeadline || remaining <= time.Second {
+		t.Fatalf("expected stats deadline of 1m, got %v (deadline=%v)", remaining, hasDeadline)
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/stats/fast", nil))
+	if hasDeadline {
+		t.Fatal("expected zero timeout on the longest prefix to disable the deadline")
+	}
+}
+
+func TestWriteErrorTimeout(t *testing.T) {
+	cases := []struct {
+		err  error
+		want int
+		code apierror.Code
+	}{
+		{context.DeadlineExceeded, http.StatusGatewayTimeout, apierror.CodeTimeout},
+		{fmt.Errorf("failed to load candidates: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, apierror.CodeTimeout},
+		{&pgconn.PgError{Code: "23505"}, http.StatusInternalServerError, apierror.CodeInternal},
+	}
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		writeError(rec, httptest.NewRequest("GET", "/team/get", nil), "test", c.err)
+		if rec.Code != c.want {
+			t.Fatalf("%v: expected %d, got %d", c.err, c.want, rec.Code)
+		}
+		var body apierror.Response
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body.Error.Code != c.code {
+			t.Fatalf("%v: expected %s body, got %+v (err=%v)", c.err, c.code, body, err)
+		}
+	}
+}
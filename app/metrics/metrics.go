@@ -0,0 +1,258 @@
+// Package metrics collects HTTP request metrics and renders them, together
+// with any other samples, in the Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// ContentType is the media type of the Prometheus text format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DurationBuckets are the upper bounds, in seconds, of the request latency
+// histogram.
+var DurationBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+type requestKey struct {
+	method, route, status string
+}
+
+type routeKey struct {
+	method, route string
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func (h *histogram) observe(v float64) {
+	for i, bound := range DurationBuckets {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+type httpMetrics struct {
+	mu        sync.Mutex
+	requests  map[requestKey]uint64
+	durations map[routeKey]*histogram
+}
+
+var defaultHTTP = &httpMetrics{
+	requests:  map[requestKey]uint64{},
+	durations: map[routeKey]*histogram{},
+}
+
+func (m *httpMetrics) observe(method, route string, status int, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[requestKey{method, route, strconv.Itoa(status)}]++
+	h, ok := m.durations[routeKey{method, route}]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(DurationBuckets))}
+		m.durations[routeKey{method, route}] = h
+	}
+	h.observe(d.Seconds())
+}
+
+func (m *httpMetrics) write(w *Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w.Header("reviewer_service_http_requests_total", "counter", "HTTP requests by method, route template and status code.")
+	keys := make([]requestKey, 0, len(m.requests))
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.route != b.route {
+			return a.route < b.route
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.status < b.status
+	})
+	for _, k := range keys {
+		w.Sample("reviewer_service_http_requests_total", float64(m.requests[k]),
+			Label{"method", k.method}, Label{"route", k.route}, Label{"status", k.status})
+	}
+
+	const name = "reviewer_service_http_request_duration_seconds"
+	w.Header(name, "histogram", "HTTP request latency by method and route template.")
+	routes := make([]routeKey, 0, len(m.durations))
+	for k := range m.durations {
+		routes = append(routes, k)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].route != routes[j].route {
+			return routes[i].route < routes[j].route
+		}
+		return routes[i].method < routes[j].method
+	})
+	for _, k := range routes {
+		h := m.durations[k]
+		method, route := Label{"method", k.method}, Label{"route", k.route}
+		for i, bound := range DurationBuckets {
+			w.Sample(name+"_bucket", float64(h.counts[i]), method, route, Label{"le", formatFloat(bound)})
+		}
+		w.Sample(name+"_bucket", float64(h.count), method, route, Label{"le", "+Inf"})
+		w.Sample(name+"_sum", h.sum, method, route)
+		w.Sample(name+"_count", float64(h.count), method, route)
+	}
+}
+
+// WriteHTTP renders the request metrics collected by Middleware.
+func WriteHTTP(w *Writer) {
+	defaultHTTP.write(w)
+}
+
+// statusRecorder captures the response status while keeping streaming
+// responses working.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Middleware records request counts and latency labelled with the matched
+// mux route template, so that path parameters do not blow up cardinality.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unmatched"
+		if cur := mux.CurrentRoute(r); cur != nil {
+			if tpl, err := cur.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+
+		rec := &statusRecorder{ResponseWriter: w}
+		start := time.Now()
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		defaultHTTP.observe(r.Method, route, rec.status, time.Since(start))
+	})
+}
+
+// Writer renders samples in the Prometheus text format. The first write
+// error is kept and reported by Err.
+type Writer struct {
+	w   io.Writer
+	err error
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+func (w *Writer) printf(format string, args ...any) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = fmt.Fprintf(w.w, format, args...)
+}
+
+// Header writes the HELP and TYPE lines of a metric family.
+func (w *Writer) Header(name, typ, help string) {
+	w.printf("# HELP %s %s\n# TYPE %s %s\n", name, escapeHelp(help), name, typ)
+}
+
+func (w *Writer) Sample(name string, value float64, labels ...Label) {
+	if len(labels) == 0 {
+		w.printf("%s %s\n", name, formatFloat(value))
+		return
+	}
+	parts := make([]string, len(labels))
+	for i, l := range labels {
+		parts[i] = fmt.Sprintf("%s=\"%s\"", l.Name, escapeLabel(l.Value))
+	}
+	w.printf("%s{%s} %s\n", name, strings.Join(parts, ","), formatFloat(value))
+}
+
+// Gauge writes a single unlabelled gauge.
+func (w *Writer) Gauge(name, help string, value float64) {
+	w.Header(name, "gauge", help)
+	w.Sample(name, value)
+}
+
+// Counter writes a single unlabelled counter.
+func (w *Writer) Counter(name, help string, value float64) {
+	w.Header(name, "counter", help)
+	w.Sample(name, value)
+}
+
+func (w *Writer) Err() error {
+	return w.err
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+func escapeHelp(v string) string {
+	return helpEscaper.Replace(v)
+}
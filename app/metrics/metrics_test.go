@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestMiddlewareUsesRouteTemplate(t *testing.T) {
+	r := mux.NewRouter()
+	r.Use(Middleware)
+	sub := r.PathPrefix("/items").Subrouter()
+	sub.HandleFunc("/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}).Methods("GET")
+
+	for _, id := range []string{"1", "2"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/items/"+id, nil))
+	}
+
+	var buf bytes.Buffer
+	WriteHTTP(NewWriter(&buf))
+	out := buf.String()
+
+	want := `reviewer_service_http_requests_total{method="GET",route="/items/{id}",status="418"} 2`
+	if !strings.Contains(out, want) {
+		t.Fatalf("expected %q in output:\n%s", want, out)
+	}
+	if !strings.Contains(out, `reviewer_service_http_request_duration_seconds_count{method="GET",route="/items/{id}"} 2`) {
+		t.Fatalf("expected histogram count in output:\n%s", out)
+	}
+}
+
+func TestWriterEscapesLabels(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Header("x", "gauge", "line one\nline two")
+	w.Sample("x", 1.5, Label{"team", `a"b\c`})
+
+	want := "# HELP x line one\\nline two\n# TYPE x gauge\nx{team=\"a\\\"b\\\\c\"} 1.5\n"
+	if buf.String() != want {
+		t.Fatalf("expected %q, got %q", want, buf.String())
+	}
+}
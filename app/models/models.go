@@ -3,21 +3,59 @@ package models
 import "time"
 
 type User struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	UserID    string `json:"user_id"`
+	Username  string `json:"username"`
+	TeamName  string `json:"team_name"`
+	IsActive  bool   `json:"is_active"`
+	Seniority string `json:"seniority,omitempty"`
 }
 
 type TeamMember struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	UserID    string `json:"user_id"`
+	Username  string `json:"username"`
+	IsActive  bool   `json:"is_active"`
+	Seniority string `json:"seniority,omitempty"`
 }
 
 type Team struct {
-	TeamName string       `json:"team_name"`
-	Members  []TeamMember `json:"members"`
+	TeamName      string       `json:"team_name"`
+	Members       []TeamMember `json:"members"`
+	FallbackTeams []string     `json:"fallback_teams,omitempty"`
+	ParentTeam    string       `json:"parent_team,omitempty"`
+	Kind          string       `json:"kind,omitempty"`
+	Policy        *TeamPolicy  `json:"policy,omitempty"`
+	// Version changes whenever the team's settings or member list do; it is
+	// also sent as the ETag. Ignored in requests.
+	Version int64 `json:"version,omitempty"`
+}
+
+// TeamPolicy holds the settings a team overrides; nil fields are inherited
+// from the parent team.
+type TeamPolicy struct {
+	ReviewerCount     *int  `json:"reviewer_count,omitempty"`
+	ReviewSLAHours    *int  `json:"review_sla_hours,omitempty"`
+	RequireSenior     *bool `json:"require_senior,omitempty"`
+	AddJuniorReviewer *bool `json:"add_junior_reviewer,omitempty"`
+}
+
+type EffectivePolicy struct {
+	ReviewerCount      int    `json:"reviewer_count"`
+	ReviewerCountFrom  string `json:"reviewer_count_from"`
+	ReviewSLAHours     *int   `json:"review_sla_hours"`
+	ReviewSLAHoursFrom string `json:"review_sla_hours_from,omitempty"`
+	RequireSenior      bool   `json:"require_senior"`
+	AddJuniorReviewer  bool   `json:"add_junior_reviewer"`
+}
+
+type TeamNode struct {
+	TeamName        string          `json:"team_name"`
+	Kind            string          `json:"kind"`
+	ParentTeam      string          `json:"parent_team,omitempty"`
+	Path            []string        `json:"path"`
+	Policy          *TeamPolicy     `json:"policy,omitempty"`
+	EffectivePolicy EffectivePolicy `json:"effective_policy"`
+	Members         []TeamMember    `json:"members"`
+	Subteams        []TeamNode      `json:"subteams"`
 }
 
 type PullRequest struct {
@@ -28,6 +66,9 @@ type PullRequest struct {
 	AssignedReviewers []string   `json:"assigned_reviewers"`
 	CreatedAt         *time.Time `json:"createdAt,omitempty"`
 	MergedAt          *time.Time `json:"mergedAt,omitempty"`
+	// Version changes whenever the status or the reviewers do; it is also
+	// sent as the ETag.
+	Version int64 `json:"version,omitempty"`
 }
 
 type PullRequestShort struct {
@@ -49,9 +90,64 @@ type MergePRRequest struct {
 	PullRequestID string `json:"pull_request_id"`
 }
 
+// ReassignPRRequest accepts the old reviewer as old_reviewer_id or, as in
+// older clients, old_user_id; Validate merges them into OldReviewerID.
 type ReassignPRRequest struct {
 	PullRequestID string `json:"pull_request_id"`
 	OldReviewerID string `json:"old_reviewer_id"`
+	OldUserID     string `json:"old_user_id,omitempty"`
+}
+
+type UpdateTeamRequest struct {
+	TeamName      string      `json:"team_name"`
+	ParentTeam    *string     `json:"parent_team"`
+	Kind          *string     `json:"kind"`
+	Policy        *TeamPolicy `json:"policy"`
+	FallbackTeams *[]string   `json:"fallback_teams"`
+}
+
+type ExplainAssignmentRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	AuthorID      string `json:"author_id"`
+	OldReviewerID string `json:"old_reviewer_id"`
+}
+
+// CandidateExplanation tells why a candidate was or was not picked. Rank is
+// the candidate's position in the search order: home team, fallback teams,
+// then ancestors' subtrees.
+type CandidateExplanation struct {
+	UserID    string `json:"user_id"`
+	TeamName  string `json:"team_name"`
+	Source    string `json:"source"`
+	Seniority string `json:"seniority"`
+	Rank      int    `json:"rank"`
+	Decision  string `json:"decision"`
+	Reason    string `json:"reason"`
+}
+
+type AssignmentExplanation struct {
+	Operation     string                 `json:"operation"`
+	PullRequestID string                 `json:"pull_request_id,omitempty"`
+	AuthorID      string                 `json:"author_id"`
+	TeamName      string                 `json:"team_name"`
+	Policy        EffectivePolicy        `json:"policy"`
+	Candidates    []CandidateExplanation `json:"candidates"`
+	Selected      []string               `json:"selected"`
+	Warnings      []string               `json:"warnings"`
+}
+
+type SubmitReviewRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	ReviewerID    string `json:"reviewer_id"`
+	Verdict       string `json:"verdict"`
+}
+
+type Review struct {
+	PullRequestID  string    `json:"pull_request_id"`
+	ReviewerID     string    `json:"reviewer_id"`
+	Verdict        string    `json:"verdict"`
+	AssignedAt     time.Time `json:"assigned_at"`
+	FirstVerdictAt time.Time `json:"first_verdict_at"`
 }
 
 type SetUserActiveRequest struct {
@@ -59,12 +155,140 @@ type SetUserActiveRequest struct {
 	IsActive bool   `json:"is_active"`
 }
 
+type SetUserSeniorityRequest struct {
+	UserID    string `json:"user_id"`
+	Seniority string `json:"seniority"`
+}
+
 type UserAssignmentStats struct {
 	UserID          string `json:"user_id"`
 	Username        string `json:"username"`
+	TeamName        string `json:"team_name"`
+	IsActive        bool   `json:"is_active"`
 	AssignedPRCount int    `json:"assigned_pr_count"`
 }
 
+type TeamAssignmentTotals struct {
+	TeamName             string  `json:"team_name"`
+	MemberCount          int     `json:"member_count"`
+	TotalAssignedPRCount int     `json:"total_assigned_pr_count"`
+	AvgAssignedPRCount   float64 `json:"avg_assigned_pr_count"`
+}
+
+// AssignmentStatsResponse reports when the numbers were computed; Cached is
+// true when they were served from the in-process stats cache.
+type AssignmentStatsResponse struct {
+	Users       []UserAssignmentStats  `json:"users"`
+	Teams       []TeamAssignmentTotals `json:"teams"`
+	GeneratedAt time.Time              `json:"generated_at"`
+	Cached      bool                   `json:"cached"`
+}
+
+type TeamAssignmentStats struct {
+	TeamName             string                `json:"team_name"`
+	Kind                 string                `json:"kind"`
+	MemberCount          int                   `json:"member_count"`
+	AssignedPRCount      int                   `json:"assigned_pr_count"`
+	TotalMemberCount     int                   `json:"total_member_count"`
+	TotalAssignedPRCount int                   `json:"total_assigned_pr_count"`
+	Subteams             []TeamAssignmentStats `json:"subteams"`
+}
+
+type SimulationRequest struct {
+	From          *time.Time `json:"from"`
+	To            *time.Time `json:"to"`
+	Strategy      string     `json:"strategy"`
+	ReviewerCount *int       `json:"reviewer_count"`
+	Seed          uint64     `json:"seed"`
+}
+
+type FairnessMetrics struct {
+	Gini        float64  `json:"gini"`
+	StdDev      float64  `json:"std_dev"`
+	Mean        float64  `json:"mean"`
+	Min         int      `json:"min"`
+	Max         int      `json:"max"`
+	MaxMinRatio *float64 `json:"max_min_ratio"`
+}
+
+type TeamFairness struct {
+	TeamName    string          `json:"team_name"`
+	MemberCount int             `json:"member_count"`
+	Assignments FairnessMetrics `json:"assignments"`
+	OpenLoad    FairnessMetrics `json:"open_load"`
+	Imbalanced  bool            `json:"imbalanced"`
+}
+
+type FairnessStatsResponse struct {
+	From          *time.Time     `json:"from,omitempty"`
+	To            *time.Time     `json:"to,omitempty"`
+	GiniThreshold float64        `json:"gini_threshold"`
+	Teams         []TeamFairness `json:"teams"`
+}
+
+type LoadDistribution struct {
+	TotalAssignments    int             `json:"total_assignments"`
+	PRsWithoutReviewers int             `json:"prs_without_reviewers"`
+	Fairness            FairnessMetrics `json:"fairness"`
+}
+
+type UserLoadComparison struct {
+	UserID         string `json:"user_id"`
+	ActualCount    int    `json:"actual_count"`
+	SimulatedCount int    `json:"simulated_count"`
+}
+
+type SimulationResult struct {
+	From             time.Time            `json:"from"`
+	To               time.Time            `json:"to"`
+	Strategy         string               `json:"strategy"`
+	ReviewerCount    *int                 `json:"reviewer_count"`
+	PullRequestCount int                  `json:"pull_request_count"`
+	Users            []UserLoadComparison `json:"users"`
+	Actual           LoadDistribution     `json:"actual"`
+	Simulated        LoadDistribution     `json:"simulated"`
+}
+
+// DurationPercentiles are reported in seconds; they are null when nothing
+// in the group has finished yet.
+type DurationPercentiles struct {
+	P50Seconds *float64 `json:"p50_seconds"`
+	P90Seconds *float64 `json:"p90_seconds"`
+	P99Seconds *float64 `json:"p99_seconds"`
+}
+
+type MergeTimeGroup struct {
+	TeamName    string `json:"team_name,omitempty"`
+	AuthorID    string `json:"author_id,omitempty"`
+	MergedCount int    `json:"merged_count"`
+	DurationPercentiles
+}
+
+type MergeTimeStats struct {
+	Teams   []MergeTimeGroup `json:"teams"`
+	Authors []MergeTimeGroup `json:"authors"`
+}
+
+type ReviewLatencyStats struct {
+	ReviewerID      string `json:"reviewer_id"`
+	AssignmentCount int    `json:"assignment_count"`
+	ReviewedCount   int    `json:"reviewed_count"`
+	DurationPercentiles
+}
+
+type WeeklyTrend struct {
+	WeekStart        time.Time `json:"week_start"`
+	CreatedCount     int       `json:"created_count"`
+	MergedCount      int       `json:"merged_count"`
+	MergeP50Seconds  *float64  `json:"merge_p50_seconds"`
+	ReviewP50Seconds *float64  `json:"review_p50_seconds"`
+}
+
+type HealthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
 type DeactivateUsersRequest struct {
 	UserIDs []string `json:"user_ids"`
 }
@@ -81,3 +305,40 @@ type DeactivationResponse struct {
 	ReassignedPRsCount  int                  `json:"reassigned_prs_count"`
 	ReassignmentDetails []ReassignmentDetail `json:"reassignment_details,omitempty"`
 }
+
+// APIToken describes an issued API token. The secret itself is only returned
+// once, when the token is issued.
+type APIToken struct {
+	TokenID   string     `json:"token_id"`
+	Name      string     `json:"name"`
+	Role      string     `json:"role"`
+	TeamName  string     `json:"team_name,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// IssueTokenRequest asks for a new token; TeamName is required for the
+// team-lead role and not allowed for the others.
+type IssueTokenRequest struct {
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	TeamName string `json:"team_name"`
+}
+
+type RevokeTokenRequest struct {
+	TokenID string `json:"token_id"`
+}
+
+// AuditEntry records one administrative action: who did it, to what, with
+// which request and how it ended. Result is "OK" or the error code.
+type AuditEntry struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Actor     string    `json:"actor"`
+	TokenID   string    `json:"token_id,omitempty"`
+	Role      string    `json:"role,omitempty"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target"`
+	Payload   string    `json:"payload,omitempty"`
+	Result    string    `json:"result"`
+}
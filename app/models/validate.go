@@ -0,0 +1,123 @@
+package models
+
+import (
+	"fmt"
+	"reviewer-service/app/validation"
+)
+
+// Validate methods check the shape of a decoded request: required fields,
+// lengths and identifier syntax. Rules that need stored data or enumerate
+// domain values (team kinds, verdicts, strategies) stay in the service.
+
+func (t *Team) Validate() error {
+	errs := validation.Errors{}
+	errs.ID("team_name", t.TeamName)
+	errs.OptionalID("parent_team", t.ParentTeam)
+	errs.IDs("fallback_teams", t.FallbackTeams)
+	errs.Check(len(t.Members) <= validation.MaxListLength, "members",
+		fmt.Sprintf("must have at most %d elements", validation.MaxListLength))
+	for i, m := range t.Members {
+		field := fmt.Sprintf("members[%d]", i)
+		errs.ID(field+".user_id", m.UserID)
+		errs.Name(field+".username", m.Username)
+	}
+	return errs.Err()
+}
+
+func (req *CreatePRRequest) Validate() error {
+	errs := validation.Errors{}
+	errs.ID("pull_request_id", req.PullRequestID)
+	errs.Name("pull_request_name", req.PullRequestName)
+	errs.ID("author_id", req.AuthorID)
+	return errs.Err()
+}
+
+func (req *MergePRRequest) Validate() error {
+	errs := validation.Errors{}
+	errs.ID("pull_request_id", req.PullRequestID)
+	return errs.Err()
+}
+
+// Validate also folds the old_user_id spelling into OldReviewerID.
+func (req *ReassignPRRequest) Validate() error {
+	errs := validation.Errors{}
+	errs.ID("pull_request_id", req.PullRequestID)
+	switch {
+	case req.OldReviewerID != "" && req.OldUserID != "" && req.OldReviewerID != req.OldUserID:
+		errs.Add("old_reviewer_id", "conflicts with old_user_id")
+	case req.OldReviewerID == "":
+		req.OldReviewerID = req.OldUserID
+	}
+	errs.ID("old_reviewer_id", req.OldReviewerID)
+	return errs.Err()
+}
+
+func (req *UpdateTeamRequest) Validate() error {
+	errs := validation.Errors{}
+	errs.ID("team_name", req.TeamName)
+	if req.ParentTeam != nil {
+		errs.OptionalID("parent_team", *req.ParentTeam)
+	}
+	if req.FallbackTeams != nil {
+		errs.IDs("fallback_teams", *req.FallbackTeams)
+	}
+	return errs.Err()
+}
+
+func (req *ExplainAssignmentRequest) Validate() error {
+	errs := validation.Errors{}
+	errs.OptionalID("pull_request_id", req.PullRequestID)
+	errs.OptionalID("author_id", req.AuthorID)
+	errs.OptionalID("old_reviewer_id", req.OldReviewerID)
+	return errs.Err()
+}
+
+func (req *SubmitReviewRequest) Validate() error {
+	errs := validation.Errors{}
+	errs.ID("pull_request_id", req.PullRequestID)
+	errs.ID("reviewer_id", req.ReviewerID)
+	errs.Check(req.Verdict != "", "verdict", "is required")
+	return errs.Err()
+}
+
+func (req *SetUserActiveRequest) Validate() error {
+	errs := validation.Errors{}
+	errs.ID("user_id", req.UserID)
+	return errs.Err()
+}
+
+func (req *SetUserSeniorityRequest) Validate() error {
+	errs := validation.Errors{}
+	errs.ID("user_id", req.UserID)
+	errs.Check(req.Seniority != "", "seniority", "is required")
+	return errs.Err()
+}
+
+func (req *DeactivateUsersRequest) Validate() error {
+	errs := validation.Errors{}
+	errs.Check(len(req.UserIDs) > 0, "user_ids", "must not be empty")
+	errs.IDs("user_ids", req.UserIDs)
+	return errs.Err()
+}
+
+func (req *SimulationRequest) Validate() error {
+	errs := validation.Errors{}
+	if req.ReviewerCount != nil {
+		errs.Check(*req.ReviewerCount >= 0, "reviewer_count", "must not be negative")
+	}
+	return errs.Err()
+}
+
+func (req *IssueTokenRequest) Validate() error {
+	errs := validation.Errors{}
+	errs.Name("name", req.Name)
+	errs.Check(req.Role != "", "role", "is required")
+	errs.OptionalID("team_name", req.TeamName)
+	return errs.Err()
+}
+
+func (req *RevokeTokenRequest) Validate() error {
+	errs := validation.Errors{}
+	errs.ID("token_id", req.TokenID)
+	return errs.Err()
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"slices"
+	"strings"
+)
+
+type auditRepo struct {
+	s *Store
+}
+
+func (r auditRepo) Append(ctx context.Context, e models.AuditEntry) error {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	e.ID, e.CreatedAt = int64(len(st.audit))+1, r.s.now()
+	st.audit = append(st.audit, e)
+	return nil
+}
+
+func (r auditRepo) List(ctx context.Context, f repository.AuditFilter) ([]models.AuditEntry, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	entries := []models.AuditEntry{}
+	for i := len(st.audit) - 1; i >= 0 && len(entries) < f.Limit; i-- {
+		e := st.audit[i]
+		if (f.Actor == "" || e.Actor == f.Actor || e.TokenID == f.Actor) &&
+			(f.Action == "" || e.Action == f.Action) &&
+			(f.Target == "" || slices.Contains(strings.Split(e.Target, ","), f.Target)) &&
+			(f.Result == "" || e.Result == f.Result) &&
+			(f.From == nil || !e.CreatedAt.Before(*f.From)) &&
+			(f.To == nil || e.CreatedAt.Before(*f.To)) &&
+			(f.BeforeID == 0 || e.ID < f.BeforeID) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
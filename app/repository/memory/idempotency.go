@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"reviewer-service/app/repository"
+	"time"
+)
+
+type idempotencyRepo struct {
+	s *Store
+}
+
+func (r idempotencyRepo) Claim(
+	ctx context.Context, key, requestHash string, expiredBefore time.Time,
+) (repository.IdempotencyRecord, bool, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return repository.IdempotencyRecord{}, false, err
+	}
+	defer unlock()
+
+	if rec, ok := st.idempotency[key]; ok && !rec.CreatedAt.Before(expiredBefore) {
+		return rec, false, nil
+	}
+	rec := repository.IdempotencyRecord{Key: key, RequestHash: requestHash, CreatedAt: r.s.now()}
+	st.idempotency[key] = rec
+	return rec, true, nil
+}
+
+func (r idempotencyRepo) Complete(ctx context.Context, rec repository.IdempotencyRecord) error {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	current, ok := st.idempotency[rec.Key]
+	if !ok || current.RequestHash != rec.RequestHash {
+		return repository.ErrNotFound
+	}
+	rec.Done, rec.CreatedAt = true, current.CreatedAt
+	st.idempotency[rec.Key] = rec
+	return nil
+}
+
+func (r idempotencyRepo) Release(ctx context.Context, key string) error {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if rec, ok := st.idempotency[key]; ok && !rec.Done {
+		delete(st.idempotency, key)
+	}
+	return nil
+}
+
+func (r idempotencyRepo) Purge(ctx context.Context, before time.Time) (int64, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	var n int64
+	for key, rec := range st.idempotency {
+		if rec.CreatedAt.Before(before) {
+			delete(st.idempotency, key)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,218 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"slices"
+	"sort"
+)
+
+type pullRequestRepo struct {
+	s *Store
+}
+
+func (r pullRequestRepo) Create(ctx context.Context, pr models.PullRequest) error {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, ok := st.prs[pr.PullRequestID]; ok {
+		return fmt.Errorf("%w: pull request %s", repository.ErrExists, pr.PullRequestID)
+	}
+	now := r.s.now()
+	pr.Status = "OPEN"
+	pr.Version = 1
+	pr.AssignedReviewers = slices.Clone(pr.AssignedReviewers)
+	if pr.AssignedReviewers == nil {
+		pr.AssignedReviewers = []string{}
+	}
+	pr.CreatedAt, pr.MergedAt = timePtr(now), nil
+	st.prs[pr.PullRequestID] = pr
+	for _, id := range pr.AssignedReviewers {
+		st.assignments = append(st.assignments, assignment{prID: pr.PullRequestID, reviewerID: id, assignedAt: now})
+	}
+	return nil
+}
+
+func (r pullRequestRepo) Get(ctx context.Context, prID string) (models.PullRequest, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return models.PullRequest{}, err
+	}
+	defer unlock()
+
+	pr, ok := st.prs[prID]
+	if !ok {
+		return models.PullRequest{}, repository.ErrNotFound
+	}
+	pr.AssignedReviewers = slices.Clone(pr.AssignedReviewers)
+	return pr, nil
+}
+
+// Lock is Get: transactions already hold the store mutex for their whole
+// duration.
+func (r pullRequestRepo) Lock(ctx context.Context, prID string) (models.PullRequest, error) {
+	return r.Get(ctx, prID)
+}
+
+func (r pullRequestRepo) Merge(ctx context.Context, prID string) error {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	pr, ok := st.prs[prID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	pr.Status, pr.MergedAt = "MERGED", timePtr(r.s.now())
+	pr.Version++
+	st.prs[prID] = pr
+	return nil
+}
+
+func (r pullRequestRepo) SetReviewers(ctx context.Context, prID string, reviewers []string) (int64, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	pr, ok := st.prs[prID]
+	if !ok {
+		return 0, repository.ErrNotFound
+	}
+	now := r.s.now()
+	open := map[string]bool{}
+	for i := range st.assignments {
+		a := &st.assignments[i]
+		if a.prID != prID || a.unassignedAt != nil {
+			continue
+		}
+		if slices.Contains(reviewers, a.reviewerID) {
+			open[a.reviewerID] = true
+		} else {
+			a.unassignedAt = timePtr(now)
+		}
+	}
+	for _, id := range reviewers {
+		if !open[id] {
+			st.assignments = append(st.assignments, assignment{prID: prID, reviewerID: id, assignedAt: now})
+		}
+	}
+	pr.AssignedReviewers = slices.Clone(reviewers)
+	pr.Version++
+	st.prs[prID] = pr
+	return pr.Version, nil
+}
+
+func (r pullRequestRepo) SubmitReview(ctx context.Context, prID, reviewerID, verdict string) (models.Review, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return models.Review{}, err
+	}
+	defer unlock()
+
+	for i := range st.assignments {
+		a := &st.assignments[i]
+		if a.prID != prID || a.reviewerID != reviewerID || a.unassignedAt != nil {
+			continue
+		}
+		a.verdict = verdict
+		if a.firstVerdictAt == nil {
+			a.firstVerdictAt = timePtr(r.s.now())
+		}
+		return models.Review{
+			PullRequestID:  prID,
+			ReviewerID:     reviewerID,
+			Verdict:        verdict,
+			AssignedAt:     a.assignedAt,
+			FirstVerdictAt: *a.firstVerdictAt,
+		}, nil
+	}
+	return models.Review{}, repository.ErrNotFound
+}
+
+// sortedPRs returns the PRs matching keep in creation order.
+func sortedPRs(st *state, keep func(models.PullRequest) bool) []models.PullRequest {
+	var prs []models.PullRequest
+	for _, pr := range st.prs {
+		if keep(pr) {
+			pr.AssignedReviewers = slices.Clone(pr.AssignedReviewers)
+			prs = append(prs, pr)
+		}
+	}
+	sort.Slice(prs, func(i, j int) bool {
+		if !prs[i].CreatedAt.Equal(*prs[j].CreatedAt) {
+			return prs[i].CreatedAt.Before(*prs[j].CreatedAt)
+		}
+		return prs[i].PullRequestID < prs[j].PullRequestID
+	})
+	return prs
+}
+
+func short(prs []models.PullRequest) []models.PullRequestShort {
+	out := []models.PullRequestShort{}
+	for _, pr := range prs {
+		out = append(out, models.PullRequestShort{
+			PullRequestID:   pr.PullRequestID,
+			PullRequestName: pr.PullRequestName,
+			AuthorID:        pr.AuthorID,
+			Status:          pr.Status,
+		})
+	}
+	return out
+}
+
+func (r pullRequestRepo) ListByReviewer(ctx context.Context, userID string) ([]models.PullRequestShort, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return short(sortedPRs(st, func(pr models.PullRequest) bool {
+		return slices.Contains(pr.AssignedReviewers, userID)
+	})), nil
+}
+
+func (r pullRequestRepo) OpenByAuthors(ctx context.Context, authorIDs []string) ([]models.PullRequestShort, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return short(sortedPRs(st, func(pr models.PullRequest) bool {
+		return pr.Status == "OPEN" && slices.Contains(authorIDs, pr.AuthorID)
+	})), nil
+}
+
+// List collects the matching PRs before calling fn, so fn may use the store.
+func (r pullRequestRepo) List(ctx context.Context, f repository.PullRequestFilter, fn func(models.PullRequest) error) error {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	prs := sortedPRs(st, func(pr models.PullRequest) bool {
+		author, ok := st.users[pr.AuthorID]
+		return ok &&
+			(f.Status == "" || pr.Status == f.Status) &&
+			(f.AuthorID == "" || pr.AuthorID == f.AuthorID) &&
+			(f.Team == "" || author.TeamName == f.Team) &&
+			inWindow(*pr.CreatedAt, f.From, f.To)
+	})
+	unlock()
+
+	for _, pr := range prs {
+		if err := fn(pr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
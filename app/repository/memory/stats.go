@@ -0,0 +1,322 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"slices"
+	"sort"
+	"time"
+)
+
+type statsRepo struct {
+	s *Store
+}
+
+func inWindow(t time.Time, from, to *time.Time) bool {
+	return (from == nil || !t.Before(*from)) && (to == nil || t.Before(*to))
+}
+
+// reviewCounts counts, per reviewer, the PRs accepted by keep that list them.
+func reviewCounts(st *state, keep func(models.PullRequest) bool) map[string]int {
+	counts := map[string]int{}
+	for _, pr := range st.prs {
+		if !keep(pr) {
+			continue
+		}
+		for _, id := range pr.AssignedReviewers {
+			counts[id]++
+		}
+	}
+	return counts
+}
+
+// percentile interpolates linearly between the closest ranks like
+// percentile_cont; values must be sorted. It is nil for an empty set.
+func percentile(values []float64, p float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	pos := p * float64(len(values)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	v := values[lo] + (pos-float64(lo))*(values[hi]-values[lo])
+	return &v
+}
+
+func durationPercentiles(seconds []float64) models.DurationPercentiles {
+	slices.Sort(seconds)
+	return models.DurationPercentiles{
+		P50Seconds: percentile(seconds, 0.5),
+		P90Seconds: percentile(seconds, 0.9),
+		P99Seconds: percentile(seconds, 0.99),
+	}
+}
+
+// weekStart truncates t to Monday 00:00 UTC like date_trunc('week', ...).
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+func (r statsRepo) Assignments(ctx context.Context, f repository.AssignmentStatsFilter, fn func(models.UserAssignmentStats) error) error {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	counts := reviewCounts(st, func(pr models.PullRequest) bool {
+		return (f.Status == "" || pr.Status == f.Status) && inWindow(*pr.CreatedAt, f.From, f.To)
+	})
+	var stats []models.UserAssignmentStats
+	for _, u := range sortedUsers(st) {
+		if (f.Team != "" && u.TeamName != f.Team) || (f.ActiveOnly && !u.IsActive) {
+			continue
+		}
+		stats = append(stats, models.UserAssignmentStats{
+			UserID:          u.UserID,
+			Username:        u.Username,
+			TeamName:        u.TeamName,
+			IsActive:        u.IsActive,
+			AssignedPRCount: counts[u.UserID],
+		})
+	}
+	unlock()
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].AssignedPRCount != stats[j].AssignedPRCount {
+			return stats[i].AssignedPRCount > stats[j].AssignedPRCount
+		}
+		return stats[i].Username < stats[j].Username
+	})
+	for _, s := range stats {
+		if err := fn(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r statsRepo) TeamAssignments(ctx context.Context, teams []string) (map[string]models.TeamAssignmentStats, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	counts := reviewCounts(st, func(models.PullRequest) bool { return true })
+	own := map[string]models.TeamAssignmentStats{}
+	for _, u := range st.users {
+		if u.TeamName == "" || !slices.Contains(teams, u.TeamName) {
+			continue
+		}
+		s := own[u.TeamName]
+		s.TeamName = u.TeamName
+		s.MemberCount++
+		s.AssignedPRCount += counts[u.UserID]
+		own[u.TeamName] = s
+	}
+	return own, nil
+}
+
+func (r statsRepo) MergeTimes(ctx context.Context, from, to *time.Time) (models.MergeTimeStats, error) {
+	stats := models.MergeTimeStats{Teams: []models.MergeTimeGroup{}, Authors: []models.MergeTimeGroup{}}
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return stats, err
+	}
+	defer unlock()
+
+	byTeam, byAuthor := map[string][]float64{}, map[string][]float64{}
+	for _, pr := range st.prs {
+		author, ok := st.users[pr.AuthorID]
+		if !ok || pr.MergedAt == nil || !inWindow(*pr.CreatedAt, from, to) {
+			continue
+		}
+		d := pr.MergedAt.Sub(*pr.CreatedAt).Seconds()
+		byTeam[author.TeamName] = append(byTeam[author.TeamName], d)
+		byAuthor[pr.AuthorID] = append(byAuthor[pr.AuthorID], d)
+	}
+	for _, team := range sortedKeys(byTeam) {
+		stats.Teams = append(stats.Teams, models.MergeTimeGroup{
+			TeamName:            team,
+			MergedCount:         len(byTeam[team]),
+			DurationPercentiles: durationPercentiles(byTeam[team]),
+		})
+	}
+	for _, author := range sortedKeys(byAuthor) {
+		stats.Authors = append(stats.Authors, models.MergeTimeGroup{
+			AuthorID:            author,
+			MergedCount:         len(byAuthor[author]),
+			DurationPercentiles: durationPercentiles(byAuthor[author]),
+		})
+	}
+	return stats, nil
+}
+
+func (r statsRepo) ReviewLatency(ctx context.Context, from, to *time.Time) ([]models.ReviewLatencyStats, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	assigned := map[string]int{}
+	reviewed := map[string][]float64{}
+	for _, a := range st.assignments {
+		if !inWindow(a.assignedAt, from, to) {
+			continue
+		}
+		assigned[a.reviewerID]++
+		if a.firstVerdictAt != nil {
+			reviewed[a.reviewerID] = append(reviewed[a.reviewerID], a.firstVerdictAt.Sub(a.assignedAt).Seconds())
+		}
+	}
+
+	stats := []models.ReviewLatencyStats{}
+	for _, id := range sortedKeys(assigned) {
+		stats = append(stats, models.ReviewLatencyStats{
+			ReviewerID:          id,
+			AssignmentCount:     assigned[id],
+			ReviewedCount:       len(reviewed[id]),
+			DurationPercentiles: durationPercentiles(reviewed[id]),
+		})
+	}
+	return stats, nil
+}
+
+func (r statsRepo) WeeklyTrends(ctx context.Context, from, to *time.Time) ([]models.WeeklyTrend, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	byWeek := map[time.Time]*models.WeeklyTrend{}
+	week := func(t time.Time) *models.WeeklyTrend {
+		w := weekStart(t)
+		if byWeek[w] == nil {
+			byWeek[w] = &models.WeeklyTrend{WeekStart: w}
+		}
+		return byWeek[w]
+	}
+
+	merge := map[time.Time][]float64{}
+	for _, pr := range st.prs {
+		if !inWindow(*pr.CreatedAt, from, to) {
+			continue
+		}
+		t := week(*pr.CreatedAt)
+		t.CreatedCount++
+		if pr.MergedAt != nil {
+			t.MergedCount++
+			merge[t.WeekStart] = append(merge[t.WeekStart], pr.MergedAt.Sub(*pr.CreatedAt).Seconds())
+		}
+	}
+	review := map[time.Time][]float64{}
+	for _, a := range st.assignments {
+		if !inWindow(a.assignedAt, from, to) {
+			continue
+		}
+		t := week(a.assignedAt)
+		if a.firstVerdictAt != nil {
+			review[t.WeekStart] = append(review[t.WeekStart], a.firstVerdictAt.Sub(a.assignedAt).Seconds())
+		}
+	}
+
+	trends := []models.WeeklyTrend{}
+	for w, t := range byWeek {
+		slices.Sort(merge[w])
+		slices.Sort(review[w])
+		t.MergeP50Seconds = percentile(merge[w], 0.5)
+		t.ReviewP50Seconds = percentile(review[w], 0.5)
+		trends = append(trends, *t)
+	}
+	sort.Slice(trends, func(i, j int) bool { return trends[i].WeekStart.Before(trends[j].WeekStart) })
+	return trends, nil
+}
+
+func (r statsRepo) ReviewLoads(ctx context.Context, from, to *time.Time, team string) ([]repository.ReviewLoad, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	assigned := reviewCounts(st, func(pr models.PullRequest) bool { return inWindow(*pr.CreatedAt, from, to) })
+	open := reviewCounts(st, func(pr models.PullRequest) bool { return pr.Status == "OPEN" })
+
+	var loads []repository.ReviewLoad
+	for _, u := range sortedUsers(st) {
+		if !u.IsActive || u.TeamName == "" || (team != "" && u.TeamName != team) {
+			continue
+		}
+		loads = append(loads, repository.ReviewLoad{
+			TeamName: u.TeamName,
+			UserID:   u.UserID,
+			Assigned: assigned[u.UserID],
+			Open:     open[u.UserID],
+		})
+	}
+	sort.SliceStable(loads, func(i, j int) bool { return loads[i].TeamName < loads[j].TeamName })
+	return loads, nil
+}
+
+func (r statsRepo) History(ctx context.Context, from, to time.Time) ([]repository.HistoricalPR, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var history []repository.HistoricalPR
+	for _, pr := range sortedPRs(st, func(pr models.PullRequest) bool {
+		_, ok := st.users[pr.AuthorID]
+		return ok && inWindow(*pr.CreatedAt, &from, &to)
+	}) {
+		history = append(history, repository.HistoricalPR{
+			AuthorID:  pr.AuthorID,
+			TeamName:  st.users[pr.AuthorID].TeamName,
+			Reviewers: pr.AssignedReviewers,
+			CreatedAt: *pr.CreatedAt,
+			MergedAt:  pr.MergedAt,
+		})
+	}
+	return history, nil
+}
+
+func (r statsRepo) OpenReviews(ctx context.Context) (repository.OpenReviewSummary, error) {
+	m := repository.OpenReviewSummary{OpenReviewLoadByTeam: map[string]int{}}
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return m, err
+	}
+	defer unlock()
+
+	for _, pr := range st.prs {
+		if pr.Status != "OPEN" {
+			continue
+		}
+		m.OpenPRs++
+		if len(pr.AssignedReviewers) == 0 {
+			m.OpenPRsNoReviewers++
+		}
+	}
+	open := reviewCounts(st, func(pr models.PullRequest) bool { return pr.Status == "OPEN" })
+	for _, u := range st.users {
+		if u.TeamName != "" {
+			m.OpenReviewLoadByTeam[u.TeamName] += open[u.UserID]
+		}
+	}
+	return m, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,132 @@
+// Package memory implements the repository interfaces in process. Nothing is
+// persisted; it backs service tests and the in-process e2e run. All
+// operations are serialized by one mutex, which a transaction holds until it
+// ends.
+package memory
+
+import (
+	"context"
+	"maps"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"slices"
+	"sync"
+	"time"
+)
+
+type team struct {
+	info      repository.TeamInfo
+	fallbacks []string
+}
+
+// assignment mirrors a review_assignments row.
+type assignment struct {
+	prID           string
+	reviewerID     string
+	assignedAt     time.Time
+	unassignedAt   *time.Time
+	verdict        string
+	firstVerdictAt *time.Time
+}
+
+// state is replaced rather than mutated in place where values hold slices or
+// pointers, so that a shallow clone is enough to roll a transaction back.
+type state struct {
+	teams       map[string]team
+	users       map[string]models.User
+	prs         map[string]models.PullRequest
+	assignments []assignment
+	idempotency map[string]repository.IdempotencyRecord
+	tokens      map[string]storedToken
+	audit       []models.AuditEntry
+}
+
+func (st *state) clone() *state {
+	return &state{
+		teams:       maps.Clone(st.teams),
+		users:       maps.Clone(st.users),
+		prs:         maps.Clone(st.prs),
+		assignments: slices.Clone(st.assignments),
+		idempotency: maps.Clone(st.idempotency),
+		tokens:      maps.Clone(st.tokens),
+		audit:       slices.Clone(st.audit),
+	}
+}
+
+type Store struct {
+	mu   *sync.Mutex
+	data *state
+	inTx bool
+	now  func() time.Time
+}
+
+var _ repository.Store = (*Store)(nil)
+
+func New() *Store {
+	return &Store{
+		mu: &sync.Mutex{},
+		data: &state{
+			teams:       map[string]team{},
+			users:       map[string]models.User{},
+			prs:         map[string]models.PullRequest{},
+			idempotency: map[string]repository.IdempotencyRecord{},
+			tokens:      map[string]storedToken{},
+		},
+		now: time.Now,
+	}
+}
+
+func (s *Store) Teams() repository.TeamRepo               { return teamRepo{s} }
+func (s *Store) Users() repository.UserRepo               { return userRepo{s} }
+func (s *Store) PullRequests() repository.PullRequestRepo { return pullRequestRepo{s} }
+func (s *Store) Stats() repository.StatsRepo              { return statsRepo{s} }
+func (s *Store) Idempotency() repository.IdempotencyRepo  { return idempotencyRepo{s} }
+func (s *Store) Tokens() repository.TokenRepo             { return tokenRepo{s} }
+func (s *Store) Audit() repository.AuditRepo              { return auditRepo{s} }
+
+// begin takes the store lock unless the caller already runs in a transaction.
+// Like a query, an operation fails once ctx is done.
+func (s *Store) begin(ctx context.Context) (*state, func(), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if s.inTx {
+		return s.data, func() {}, nil
+	}
+	s.mu.Lock()
+	return s.data, s.mu.Unlock, nil
+}
+
+func (s *Store) InTx(ctx context.Context, fn func(tx repository.Store) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.data.clone()
+	err := fn(&Store{mu: s.mu, data: s.data, inTx: true, now: s.now})
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		*s.data = *snapshot
+	}
+	return err
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (s *Store) CheckSchema(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// timePtr returns a pointer to a copy of t.
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"testing"
+	"time"
+)
+
+func TestInTxRollsBackOnError(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	if err := s.Teams().Create(ctx, models.Team{TeamName: "core", Kind: "team"}); err != nil {
+		t.Fatalf("create team: %v", err)
+	}
+
+	boom := errors.New("boom")
+	err := s.InTx(ctx, func(tx repository.Store) error {
+		if err := tx.Users().Upsert(ctx, "core", models.TeamMember{UserID: "u1", Username: "Alice", IsActive: true}); err != nil {
+			return err
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected boom, got %v", err)
+	}
+	if _, err := s.Users().Get(ctx, "u1"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected rolled back user to be missing, got %v", err)
+	}
+}
+
+func TestCandidateTiersKeepsBestTier(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	for _, team := range []models.Team{
+		{TeamName: "org", Kind: "org"},
+		{TeamName: "backend", ParentTeam: "org", Kind: "team", FallbackTeams: []string{"frontend"}},
+		{TeamName: "frontend", ParentTeam: "org", Kind: "team"},
+		{TeamName: "infra", ParentTeam: "org", Kind: "team"},
+	} {
+		if err := s.Teams().Create(ctx, team); err != nil {
+			t.Fatalf("create team %s: %v", team.TeamName, err)
+		}
+	}
+	for team, user := range map[string]string{"backend": "b1", "frontend": "f1", "infra": "i1"} {
+		if err := s.Users().Upsert(ctx, team, models.TeamMember{UserID: user, IsActive: true}); err != nil {
+			t.Fatalf("upsert %s: %v", user, err)
+		}
+	}
+
+	tiers, err := s.Teams().CandidateTiers(ctx, "backend")
+	if err != nil {
+		t.Fatalf("candidate tiers: %v", err)
+	}
+	var got []string
+	for _, tier := range tiers {
+		got = append(got, tier.TeamName+"/"+tier.Source)
+	}
+	want := []string{"backend/home", "frontend/fallback", "infra/hierarchy"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestPercentileInterpolates(t *testing.T) {
+	if p := percentile(nil, 0.5); p != nil {
+		t.Fatalf("expected nil for empty set, got %v", *p)
+	}
+	if p := percentile([]float64{10, 20, 30, 40}, 0.5); p == nil || *p != 25 {
+		t.Fatalf("expected 25, got %v", p)
+	}
+}
+
+func TestWeekStartIsMondayUTC(t *testing.T) {
+	sunday := time.Date(2024, 3, 10, 23, 0, 0, 0, time.UTC)
+	if got := weekStart(sunday); !got.Equal(time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected Monday 2024-03-04, got %v", got)
+	}
+}
@@ -0,0 +1,252 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"slices"
+	"sort"
+)
+
+type teamRepo struct {
+	s *Store
+}
+
+func (r teamRepo) Create(ctx context.Context, t models.Team) error {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, ok := st.teams[t.TeamName]; ok {
+		return fmt.Errorf("%w: team %s", repository.ErrExists, t.TeamName)
+	}
+	var policy *models.TeamPolicy
+	if t.Policy != nil && *t.Policy != (models.TeamPolicy{}) {
+		p := *t.Policy
+		policy = &p
+	}
+	st.teams[t.TeamName] = team{
+		info:      repository.TeamInfo{Name: t.TeamName, Parent: t.ParentTeam, Kind: t.Kind, Policy: policy, Version: 1},
+		fallbacks: slices.Clone(t.FallbackTeams),
+	}
+	return nil
+}
+
+func (r teamRepo) Get(ctx context.Context, name string) (models.Team, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return models.Team{}, err
+	}
+	defer unlock()
+
+	t, ok := st.teams[name]
+	if !ok {
+		return models.Team{}, repository.ErrNotFound
+	}
+	members := membersOf(st, []string{name})[name]
+	if members == nil {
+		members = []models.TeamMember{}
+	}
+	var fallbacks []string
+	if len(t.fallbacks) > 0 {
+		fallbacks = slices.Clone(t.fallbacks)
+	}
+	return models.Team{
+		TeamName:      t.info.Name,
+		Members:       members,
+		FallbackTeams: fallbacks,
+		ParentTeam:    t.info.Parent,
+		Kind:          t.info.Kind,
+		Policy:        t.info.Policy,
+		Version:       t.info.Version,
+	}, nil
+}
+
+func (r teamRepo) Lock(ctx context.Context, name string) (repository.TeamInfo, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return repository.TeamInfo{}, err
+	}
+	defer unlock()
+
+	t, ok := st.teams[name]
+	if !ok {
+		return repository.TeamInfo{}, repository.ErrNotFound
+	}
+	return t.info, nil
+}
+
+// LockHierarchy has nothing to do: a transaction already holds the store
+// lock.
+func (r teamRepo) LockHierarchy(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (r teamRepo) Update(ctx context.Context, req models.UpdateTeamRequest) error {
+	if req.Kind == nil {
+		return errors.New("team update requires kind")
+	}
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	t, ok := st.teams[req.TeamName]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	t.info.Kind = *req.Kind
+	t.info.Version++
+	if req.ParentTeam != nil {
+		t.info.Parent = *req.ParentTeam
+	}
+	if req.Policy != nil {
+		t.info.Policy = nil
+		if *req.Policy != (models.TeamPolicy{}) {
+			p := *req.Policy
+			t.info.Policy = &p
+		}
+	}
+	if req.FallbackTeams != nil {
+		t.fallbacks = slices.Clone(*req.FallbackTeams)
+	}
+	st.teams[req.TeamName] = t
+	return nil
+}
+
+func (r teamRepo) List(ctx context.Context) ([]repository.TeamInfo, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	teams := make([]repository.TeamInfo, 0, len(st.teams))
+	for _, t := range st.teams {
+		teams = append(teams, t.info)
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i].Name < teams[j].Name })
+	return teams, nil
+}
+
+func (r teamRepo) Chain(ctx context.Context, name string) ([]repository.TeamInfo, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var chain []repository.TeamInfo
+	for name != "" && len(chain) <= repository.MaxHierarchyDepth {
+		t, ok := st.teams[name]
+		if !ok {
+			break
+		}
+		chain = append(chain, t.info)
+		name = t.info.Parent
+	}
+	return chain, nil
+}
+
+func (r teamRepo) FirstMissing(ctx context.Context, names []string) (string, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	for _, name := range names {
+		if _, ok := st.teams[name]; !ok {
+			return name, nil
+		}
+	}
+	return "", nil
+}
+
+// CandidateTiers follows the same rules as the recursive query of the
+// postgres store: a team reachable several ways keeps its best tier.
+func (r teamRepo) CandidateTiers(ctx context.Context, name string) ([]repository.CandidateTier, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	type tier struct {
+		source         string
+		rank, priority int
+	}
+	best := map[string]tier{}
+	offer := func(team string, t tier) {
+		if cur, ok := best[team]; !ok || t.rank < cur.rank || (t.rank == cur.rank && t.priority < cur.priority) {
+			best[team] = t
+		}
+	}
+
+	offer(name, tier{"home", 0, 0})
+	for i, fb := range st.teams[name].fallbacks {
+		offer(fb, tier{"fallback", 1, i + 1})
+	}
+
+	children := map[string][]string{}
+	for _, t := range st.teams {
+		if t.info.Parent != "" {
+			children[t.info.Parent] = append(children[t.info.Parent], t.info.Name)
+		}
+	}
+	ancestor := st.teams[name].info.Parent
+	for depth := 1; ancestor != "" && depth <= repository.MaxHierarchyDepth; depth++ {
+		subtree := []string{ancestor}
+		seen := map[string]bool{ancestor: true}
+		for i := 0; i < len(subtree); i++ {
+			for _, child := range children[subtree[i]] {
+				if !seen[child] {
+					seen[child] = true
+					subtree = append(subtree, child)
+				}
+			}
+		}
+		for _, team := range subtree {
+			offer(team, tier{"hierarchy", 2, depth})
+		}
+		ancestor = st.teams[ancestor].info.Parent
+	}
+
+	teams := make([]string, 0, len(best))
+	for team := range best {
+		teams = append(teams, team)
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		a, b := best[teams[i]], best[teams[j]]
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		if a.priority != b.priority {
+			return a.priority < b.priority
+		}
+		return teams[i] < teams[j]
+	})
+
+	members := membersOf(st, teams)
+	var tiers []repository.CandidateTier
+	for _, team := range teams {
+		if len(members[team]) == 0 {
+			continue
+		}
+		ct := repository.CandidateTier{TeamName: team, Source: best[team].source, CrossTeam: team != name}
+		for _, m := range members[team] {
+			ct.Candidates = append(ct.Candidates, repository.Candidate{
+				UserID:    m.UserID,
+				Seniority: m.Seniority,
+				IsActive:  m.IsActive,
+			})
+		}
+		tiers = append(tiers, ct)
+	}
+	return tiers, nil
+}
@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+)
+
+// storedToken mirrors an api_tokens row.
+type storedToken struct {
+	token models.APIToken
+	hash  string
+}
+
+type tokenRepo struct {
+	s *Store
+}
+
+func (r tokenRepo) Create(ctx context.Context, token models.APIToken, hash string) error {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, ok := st.tokens[token.TokenID]; ok {
+		return fmt.Errorf("%w: token %s", repository.ErrExists, token.TokenID)
+	}
+	for _, t := range st.tokens {
+		if t.hash == hash {
+			return fmt.Errorf("%w: token hash", repository.ErrExists)
+		}
+	}
+	st.tokens[token.TokenID] = storedToken{token: token, hash: hash}
+	return nil
+}
+
+func (r tokenRepo) GetByHash(ctx context.Context, hash string) (models.APIToken, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return models.APIToken{}, err
+	}
+	defer unlock()
+
+	for _, t := range st.tokens {
+		if t.hash == hash {
+			return t.token, nil
+		}
+	}
+	return models.APIToken{}, repository.ErrNotFound
+}
+
+func (r tokenRepo) Revoke(ctx context.Context, tokenID string) (models.APIToken, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return models.APIToken{}, err
+	}
+	defer unlock()
+
+	t, ok := st.tokens[tokenID]
+	if !ok {
+		return models.APIToken{}, repository.ErrNotFound
+	}
+	if t.token.RevokedAt == nil {
+		t.token.RevokedAt = timePtr(r.s.now())
+		st.tokens[tokenID] = t
+	}
+	return t.token, nil
+}
@@ -0,0 +1,170 @@
+package memory
+
+import (
+	"context"
+	"math/rand/v2"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"slices"
+	"sort"
+)
+
+type userRepo struct {
+	s *Store
+}
+
+func (r userRepo) Upsert(ctx context.Context, teamName string, m models.TeamMember) error {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if old, ok := st.users[m.UserID]; ok && old.TeamName != teamName {
+		touchTeam(st, old.TeamName)
+	}
+	st.users[m.UserID] = models.User{
+		UserID:    m.UserID,
+		Username:  m.Username,
+		TeamName:  teamName,
+		IsActive:  m.IsActive,
+		Seniority: m.Seniority,
+	}
+	return nil
+}
+
+func (r userRepo) Get(ctx context.Context, userID string) (models.User, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return models.User{}, err
+	}
+	defer unlock()
+
+	u, ok := st.users[userID]
+	if !ok {
+		return models.User{}, repository.ErrNotFound
+	}
+	return u, nil
+}
+
+func (r userRepo) update(ctx context.Context, userID string, fn func(*models.User)) error {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	u, ok := st.users[userID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	fn(&u)
+	st.users[userID] = u
+	touchTeam(st, u.TeamName)
+	return nil
+}
+
+// touchTeam increments the version of the team, if there is one.
+func touchTeam(st *state, name string) {
+	if t, ok := st.teams[name]; ok {
+		t.info.Version++
+		st.teams[name] = t
+	}
+}
+
+func (r userRepo) SetActive(ctx context.Context, userID string, active bool) error {
+	return r.update(ctx, userID, func(u *models.User) { u.IsActive = active })
+}
+
+func (r userRepo) SetSeniority(ctx context.Context, userID, seniority string) error {
+	return r.update(ctx, userID, func(u *models.User) { u.Seniority = seniority })
+}
+
+func (r userRepo) MembersOf(ctx context.Context, teams []string) (map[string][]models.TeamMember, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return membersOf(st, teams), nil
+}
+
+func membersOf(st *state, teams []string) map[string][]models.TeamMember {
+	members := map[string][]models.TeamMember{}
+	for _, u := range sortedUsers(st) {
+		if u.TeamName != "" && slices.Contains(teams, u.TeamName) {
+			members[u.TeamName] = append(members[u.TeamName], models.TeamMember{
+				UserID:    u.UserID,
+				Username:  u.Username,
+				IsActive:  u.IsActive,
+				Seniority: u.Seniority,
+			})
+		}
+	}
+	return members
+}
+
+func sortedUsers(st *state) []models.User {
+	users := make([]models.User, 0, len(st.users))
+	for _, u := range st.users {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].UserID < users[j].UserID })
+	return users
+}
+
+func (r userRepo) Deactivate(ctx context.Context, userIDs []string) ([]string, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var deactivated []string
+	for _, id := range userIDs {
+		u, ok := st.users[id]
+		if !ok || !u.IsActive {
+			continue
+		}
+		u.IsActive = false
+		st.users[id] = u
+		touchTeam(st, u.TeamName)
+		deactivated = append(deactivated, id)
+	}
+	return deactivated, nil
+}
+
+func (r userRepo) AnySenior(ctx context.Context, userIDs []string) (bool, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	for _, id := range userIDs {
+		if u, ok := st.users[id]; ok && u.Seniority == "senior" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r userRepo) RandomActiveTeammate(ctx context.Context, team, exclude string) (string, error) {
+	st, unlock, err := r.s.begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	var candidates []string
+	for _, u := range sortedUsers(st) {
+		if u.TeamName == team && u.IsActive && u.UserID != exclude {
+			candidates = append(candidates, u.UserID)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", repository.ErrNotFound
+	}
+	return candidates[rand.IntN(len(candidates))], nil //nolint:gosec // reviewer choice, not security sensitive
+}
@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+)
+
+type auditRepo struct {
+	q dbtx
+}
+
+func (r auditRepo) Append(ctx context.Context, e models.AuditEntry) error {
+	_, err := r.q.Exec(ctx, `
+		INSERT INTO audit_log(actor, token_id, role, action, target, payload, result)
+		VALUES($1, $2, $3, $4, $5, $6, $7)
+	`, e.Actor, e.TokenID, e.Role, e.Action, e.Target, e.Payload, e.Result)
+	return err
+}
+
+func (r auditRepo) List(ctx context.Context, f repository.AuditFilter) ([]models.AuditEntry, error) {
+	rows, err := r.q.Query(ctx, `
+		SELECT id, created_at, actor, token_id, role, action, target, payload, result
+		FROM audit_log
+		WHERE ($1 = '' OR actor = $1 OR token_id = $1)
+			AND ($2 = '' OR action = $2)
+			AND ($3 = '' OR $3 = ANY(string_to_array(target, ',')))
+			AND ($4 = '' OR result = $4)
+			AND ($5::timestamptz IS NULL OR created_at >= $5)
+			AND ($6::timestamptz IS NULL OR created_at < $6)
+			AND ($7 = 0 OR id < $7)
+		ORDER BY id DESC
+		LIMIT $8
+	`, f.Actor, f.Action, f.Target, f.Result, f.From, f.To, f.BeforeID, f.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		var e models.AuditEntry
+		if err := rows.Scan(&e.ID, &e.CreatedAt, &e.Actor, &e.TokenID, &e.Role,
+			&e.Action, &e.Target, &e.Payload, &e.Result); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"reviewer-service/app/repository"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type idempotencyRepo struct {
+	q dbtx
+}
+
+func (r idempotencyRepo) Claim(
+	ctx context.Context, key, requestHash string, expiredBefore time.Time,
+) (repository.IdempotencyRecord, bool, error) {
+	if _, err := r.q.Exec(ctx, "DELETE FROM idempotency_keys WHERE key=$1 AND created_at < $2", key, expiredBefore); err != nil {
+		return repository.IdempotencyRecord{}, false, err
+	}
+
+	rec := repository.IdempotencyRecord{Key: key, RequestHash: requestHash}
+	err := r.q.QueryRow(ctx, `
+		INSERT INTO idempotency_keys(key, request_hash) VALUES($1, $2)
+		ON CONFLICT (key) DO NOTHING
+		RETURNING created_at
+	`, key, requestHash).Scan(&rec.CreatedAt)
+	if err == nil {
+		return rec, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return rec, false, err
+	}
+
+	// Someone else holds the key.
+	var status *int
+	err = r.q.QueryRow(ctx, `
+		SELECT request_hash, status, content_type, etag, COALESCE(body, ''), created_at
+		FROM idempotency_keys WHERE key=$1
+	`, key).Scan(&rec.RequestHash, &status, &rec.ContentType, &rec.ETag, &rec.Body, &rec.CreatedAt)
+	if status != nil {
+		rec.Done, rec.Status = true, *status
+	}
+	return rec, false, notFound(err)
+}
+
+func (r idempotencyRepo) Complete(ctx context.Context, rec repository.IdempotencyRecord) error {
+	tag, err := r.q.Exec(ctx, `
+		UPDATE idempotency_keys SET status=$2, content_type=$3, etag=$4, body=$5
+		WHERE key=$1 AND request_hash=$6
+	`, rec.Key, rec.Status, rec.ContentType, rec.ETag, rec.Body, rec.RequestHash)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r idempotencyRepo) Release(ctx context.Context, key string) error {
+	_, err := r.q.Exec(ctx, "DELETE FROM idempotency_keys WHERE key=$1 AND status IS NULL", key)
+	return err
+}
+
+func (r idempotencyRepo) Purge(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.q.Exec(ctx, "DELETE FROM idempotency_keys WHERE created_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,174 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type pullRequestRepo struct {
+	q dbtx
+}
+
+func (r pullRequestRepo) Create(ctx context.Context, pr models.PullRequest) error {
+	_, err := r.q.Exec(ctx, `
+		WITH pr AS (
+			INSERT INTO pull_requests(pull_request_id, pull_request_name, author_id, status, assigned_reviewers)
+			VALUES($1,$2,$3,'OPEN',$4)
+			RETURNING pull_request_id
+		)
+		INSERT INTO review_assignments(pull_request_id, reviewer_id)
+		SELECT pr.pull_request_id, reviewer_id FROM pr, unnest($4::text[]) AS reviewer_id
+	`, pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.AssignedReviewers)
+	return uniqueViolation(err)
+}
+
+const pullRequestColumns = `p.pull_request_id, p.pull_request_name, p.author_id, p.status,
+	COALESCE(p.assigned_reviewers, '{}'), p.created_at, p.merged_at, p.version`
+
+func scanPullRequest(row pgx.Row) (models.PullRequest, error) {
+	var pr models.PullRequest
+	err := row.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status,
+		&pr.AssignedReviewers, &pr.CreatedAt, &pr.MergedAt, &pr.Version)
+	if pr.AssignedReviewers == nil {
+		pr.AssignedReviewers = []string{}
+	}
+	return pr, err
+}
+
+func (r pullRequestRepo) Get(ctx context.Context, prID string) (models.PullRequest, error) {
+	pr, err := scanPullRequest(r.q.QueryRow(ctx, `
+		SELECT `+pullRequestColumns+`
+		FROM pull_requests p
+		WHERE p.pull_request_id=$1
+	`, prID))
+	return pr, notFound(err)
+}
+
+func (r pullRequestRepo) Lock(ctx context.Context, prID string) (models.PullRequest, error) {
+	pr, err := scanPullRequest(r.q.QueryRow(ctx, `
+		SELECT `+pullRequestColumns+`
+		FROM pull_requests p
+		WHERE p.pull_request_id=$1
+		FOR UPDATE
+	`, prID))
+	return pr, notFound(err)
+}
+
+func (r pullRequestRepo) Merge(ctx context.Context, prID string) error {
+	tag, err := r.q.Exec(ctx, `
+		UPDATE pull_requests
+		SET status='MERGED', merged_at=NOW(), version=version+1
+		WHERE pull_request_id=$1
+	`, prID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r pullRequestRepo) SetReviewers(ctx context.Context, prID string, reviewers []string) (int64, error) {
+	// The statement sees review_assignments as it was before the update, so
+	// reviewers who stay keep their open assignment and are not added twice.
+	var version int64
+	err := r.q.QueryRow(ctx, `
+		WITH pr AS (
+			UPDATE pull_requests SET assigned_reviewers=$2, version=version+1 WHERE pull_request_id=$1
+			RETURNING pull_request_id, version
+		), unassigned AS (
+			UPDATE review_assignments SET unassigned_at=NOW()
+			WHERE pull_request_id=$1 AND unassigned_at IS NULL AND NOT (reviewer_id = ANY($2))
+		), assigned AS (
+			INSERT INTO review_assignments(pull_request_id, reviewer_id)
+			SELECT pr.pull_request_id, r.reviewer_id FROM pr, unnest($2::text[]) AS r(reviewer_id)
+			WHERE NOT EXISTS (
+				SELECT 1 FROM review_assignments a
+				WHERE a.pull_request_id = $1 AND a.reviewer_id = r.reviewer_id AND a.unassigned_at IS NULL
+			)
+		)
+		SELECT version FROM pr
+	`, prID, reviewers).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update reviewers: %w", notFound(err))
+	}
+	return version, nil
+}
+
+func (r pullRequestRepo) SubmitReview(ctx context.Context, prID, reviewerID, verdict string) (models.Review, error) {
+	review := models.Review{PullRequestID: prID, ReviewerID: reviewerID, Verdict: verdict}
+	err := r.q.QueryRow(ctx, `
+		UPDATE review_assignments
+		SET verdict=$1, verdict_at=NOW(), first_verdict_at=COALESCE(first_verdict_at, NOW())
+		WHERE pull_request_id=$2 AND reviewer_id=$3 AND unassigned_at IS NULL
+		RETURNING assigned_at, first_verdict_at
+	`, verdict, prID, reviewerID).Scan(&review.AssignedAt, &review.FirstVerdictAt)
+	return review, notFound(err)
+}
+
+func collectShort(rows pgx.Rows, err error) ([]models.PullRequestShort, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prs := []models.PullRequestShort{}
+	for rows.Next() {
+		var pr models.PullRequestShort
+		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status); err != nil {
+			return nil, fmt.Errorf("failed to scan PR: %w", err)
+		}
+		prs = append(prs, pr)
+	}
+	return prs, rows.Err()
+}
+
+func (r pullRequestRepo) ListByReviewer(ctx context.Context, userID string) ([]models.PullRequestShort, error) {
+	return collectShort(r.q.Query(ctx, `
+		SELECT pull_request_id, pull_request_name, author_id, status
+		FROM pull_requests
+		WHERE $1 = ANY(assigned_reviewers)
+	`, userID))
+}
+
+func (r pullRequestRepo) OpenByAuthors(ctx context.Context, authorIDs []string) ([]models.PullRequestShort, error) {
+	return collectShort(r.q.Query(ctx, `
+		SELECT pull_request_id, pull_request_name, author_id, status
+		FROM pull_requests
+		WHERE author_id = ANY($1) AND status = 'OPEN'
+	`, authorIDs))
+}
+
+func (r pullRequestRepo) List(ctx context.Context, f repository.PullRequestFilter, fn func(models.PullRequest) error) error {
+	rows, err := r.q.Query(ctx, `
+		SELECT `+pullRequestColumns+`
+		FROM pull_requests p
+		JOIN users u ON u.user_id = p.author_id
+		WHERE ($1 = '' OR p.status = $1)
+			AND ($2 = '' OR p.author_id = $2)
+			AND ($3 = '' OR u.team_name = $3)
+			AND ($4::timestamptz IS NULL OR p.created_at >= $4)
+			AND ($5::timestamptz IS NULL OR p.created_at < $5)
+		ORDER BY p.created_at, p.pull_request_id
+	`, f.Status, f.AuthorID, f.Team, f.From, f.To)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		pr, err := scanPullRequest(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan PR: %w", err)
+		}
+		if err := fn(pr); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,324 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"time"
+)
+
+type statsRepo struct {
+	q dbtx
+}
+
+func (r statsRepo) Assignments(ctx context.Context, f repository.AssignmentStatsFilter, fn func(models.UserAssignmentStats) error) error {
+	rows, err := r.q.Query(ctx, `
+		SELECT
+			u.user_id,
+			u.username,
+			COALESCE(u.team_name, ''),
+			u.is_active,
+			COUNT(reviewers.reviewer_id) AS assigned_pr_count
+		FROM
+			users u
+		LEFT JOIN
+			(
+				SELECT unnest(assigned_reviewers) AS reviewer_id FROM pull_requests
+				WHERE ($1 = '' OR status = $1)
+					AND ($2::timestamptz IS NULL OR created_at >= $2)
+					AND ($3::timestamptz IS NULL OR created_at < $3)
+			) AS reviewers
+			ON u.user_id = reviewers.reviewer_id
+		WHERE
+			($4 = '' OR u.team_name = $4)
+			AND (NOT $5 OR u.is_active)
+		GROUP BY
+			u.user_id, u.username, u.team_name, u.is_active
+		ORDER BY
+			assigned_pr_count DESC, u.username ASC, u.user_id ASC
+	`, f.Status, f.From, f.To, f.Team, f.ActiveOnly)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s models.UserAssignmentStats
+		if err := rows.Scan(&s.UserID, &s.Username, &s.TeamName, &s.IsActive, &s.AssignedPRCount); err != nil {
+			return fmt.Errorf("failed to scan stats row: %w", err)
+		}
+		if err := fn(s); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r statsRepo) TeamAssignments(ctx context.Context, teams []string) (map[string]models.TeamAssignmentStats, error) {
+	rows, err := r.q.Query(ctx, `
+		SELECT
+			u.team_name,
+			COUNT(DISTINCT u.user_id) AS member_count,
+			COUNT(reviewers.reviewer_id) AS assigned_pr_count
+		FROM
+			users u
+		LEFT JOIN
+			(SELECT unnest(assigned_reviewers) AS reviewer_id FROM pull_requests) AS reviewers
+			ON u.user_id = reviewers.reviewer_id
+		WHERE
+			u.team_name = ANY($1)
+		GROUP BY
+			u.team_name
+	`, teams)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	own := map[string]models.TeamAssignmentStats{}
+	for rows.Next() {
+		var s models.TeamAssignmentStats
+		if err := rows.Scan(&s.TeamName, &s.MemberCount, &s.AssignedPRCount); err != nil {
+			return nil, fmt.Errorf("failed to scan team stats row: %w", err)
+		}
+		own[s.TeamName] = s
+	}
+	return own, rows.Err()
+}
+
+func (r statsRepo) MergeTimes(ctx context.Context, from, to *time.Time) (models.MergeTimeStats, error) {
+	stats := models.MergeTimeStats{Teams: []models.MergeTimeGroup{}, Authors: []models.MergeTimeGroup{}}
+	rows, err := r.q.Query(ctx, `
+		SELECT
+			GROUPING(u.team_name) = 0 AS by_team,
+			COALESCE(u.team_name, ''),
+			COALESCE(p.author_id, ''),
+			COUNT(*),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM p.merged_at - p.created_at)),
+			percentile_cont(0.9) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM p.merged_at - p.created_at)),
+			percentile_cont(0.99) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM p.merged_at - p.created_at))
+		FROM
+			pull_requests p
+		JOIN
+			users u ON u.user_id = p.author_id
+		WHERE
+			p.merged_at IS NOT NULL
+			AND ($1::timestamptz IS NULL OR p.created_at >= $1)
+			AND ($2::timestamptz IS NULL OR p.created_at < $2)
+		GROUP BY
+			GROUPING SETS ((u.team_name), (p.author_id))
+		ORDER BY
+			2, 3
+	`, from, to)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var byTeam bool
+		var g models.MergeTimeGroup
+		if err := rows.Scan(&byTeam, &g.TeamName, &g.AuthorID, &g.MergedCount, &g.P50Seconds, &g.P90Seconds, &g.P99Seconds); err != nil {
+			return stats, fmt.Errorf("failed to scan merge time row: %w", err)
+		}
+		if byTeam {
+			stats.Teams = append(stats.Teams, g)
+		} else {
+			stats.Authors = append(stats.Authors, g)
+		}
+	}
+	return stats, rows.Err()
+}
+
+func (r statsRepo) ReviewLatency(ctx context.Context, from, to *time.Time) ([]models.ReviewLatencyStats, error) {
+	rows, err := r.q.Query(ctx, `
+		SELECT
+			a.reviewer_id,
+			COUNT(*),
+			COUNT(a.first_verdict_at),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM a.first_verdict_at - a.assigned_at)),
+			percentile_cont(0.9) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM a.first_verdict_at - a.assigned_at)),
+			percentile_cont(0.99) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM a.first_verdict_at - a.assigned_at))
+		FROM
+			review_assignments a
+		WHERE
+			($1::timestamptz IS NULL OR a.assigned_at >= $1)
+			AND ($2::timestamptz IS NULL OR a.assigned_at < $2)
+		GROUP BY
+			a.reviewer_id
+		ORDER BY
+			a.reviewer_id
+	`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []models.ReviewLatencyStats{}
+	for rows.Next() {
+		var s models.ReviewLatencyStats
+		if err := rows.Scan(&s.ReviewerID, &s.AssignmentCount, &s.ReviewedCount, &s.P50Seconds, &s.P90Seconds, &s.P99Seconds); err != nil {
+			return nil, fmt.Errorf("failed to scan review latency row: %w", err)
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+func (r statsRepo) WeeklyTrends(ctx context.Context, from, to *time.Time) ([]models.WeeklyTrend, error) {
+	rows, err := r.q.Query(ctx, `
+		WITH created AS (
+			SELECT
+				date_trunc('week', created_at) AS week,
+				COUNT(*) AS created_count,
+				COUNT(merged_at) AS merged_count,
+				percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM merged_at - created_at)) AS merge_p50
+			FROM pull_requests
+			WHERE ($1::timestamptz IS NULL OR created_at >= $1)
+				AND ($2::timestamptz IS NULL OR created_at < $2)
+			GROUP BY 1
+		), reviewed AS (
+			SELECT
+				date_trunc('week', assigned_at) AS week,
+				percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM first_verdict_at - assigned_at)) AS review_p50
+			FROM review_assignments
+			WHERE ($1::timestamptz IS NULL OR assigned_at >= $1)
+				AND ($2::timestamptz IS NULL OR assigned_at < $2)
+			GROUP BY 1
+		)
+		SELECT
+			COALESCE(c.week, r.week),
+			COALESCE(c.created_count, 0),
+			COALESCE(c.merged_count, 0),
+			c.merge_p50,
+			r.review_p50
+		FROM created c
+		FULL JOIN reviewed r ON r.week = c.week
+		ORDER BY 1
+	`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	trends := []models.WeeklyTrend{}
+	for rows.Next() {
+		var t models.WeeklyTrend
+		if err := rows.Scan(&t.WeekStart, &t.CreatedCount, &t.MergedCount, &t.MergeP50Seconds, &t.ReviewP50Seconds); err != nil {
+			return nil, fmt.Errorf("failed to scan weekly trend row: %w", err)
+		}
+		trends = append(trends, t)
+	}
+	return trends, rows.Err()
+}
+
+func (r statsRepo) ReviewLoads(ctx context.Context, from, to *time.Time, team string) ([]repository.ReviewLoad, error) {
+	rows, err := r.q.Query(ctx, `
+		SELECT
+			u.team_name,
+			u.user_id,
+			COUNT(reviewers.reviewer_id) FILTER (
+				WHERE ($1::timestamptz IS NULL OR reviewers.created_at >= $1)
+					AND ($2::timestamptz IS NULL OR reviewers.created_at < $2)
+			) AS assigned_pr_count,
+			COUNT(reviewers.reviewer_id) FILTER (WHERE reviewers.status = 'OPEN') AS open_pr_count
+		FROM
+			users u
+		LEFT JOIN
+			(
+				SELECT unnest(assigned_reviewers) AS reviewer_id, status, created_at FROM pull_requests
+			) AS reviewers
+			ON u.user_id = reviewers.reviewer_id
+		WHERE
+			u.is_active
+			AND u.team_name IS NOT NULL
+			AND ($3 = '' OR u.team_name = $3)
+		GROUP BY
+			u.team_name, u.user_id
+		ORDER BY
+			u.team_name, u.user_id
+	`, from, to, team)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var loads []repository.ReviewLoad
+	for rows.Next() {
+		var l repository.ReviewLoad
+		if err := rows.Scan(&l.TeamName, &l.UserID, &l.Assigned, &l.Open); err != nil {
+			return nil, fmt.Errorf("failed to scan fairness row: %w", err)
+		}
+		loads = append(loads, l)
+	}
+	return loads, rows.Err()
+}
+
+func (r statsRepo) History(ctx context.Context, from, to time.Time) ([]repository.HistoricalPR, error) {
+	rows, err := r.q.Query(ctx, `
+		SELECT p.author_id, COALESCE(u.team_name, ''), COALESCE(p.assigned_reviewers, '{}'), p.created_at, p.merged_at
+		FROM pull_requests p
+		JOIN users u ON u.user_id = p.author_id
+		WHERE p.created_at >= $1 AND p.created_at < $2
+		ORDER BY p.created_at, p.pull_request_id
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load PR history: %w", err)
+	}
+	defer rows.Close()
+
+	var prs []repository.HistoricalPR
+	for rows.Next() {
+		var pr repository.HistoricalPR
+		if err := rows.Scan(&pr.AuthorID, &pr.TeamName, &pr.Reviewers, &pr.CreatedAt, &pr.MergedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan PR history: %w", err)
+		}
+		prs = append(prs, pr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during iteration over PR history: %w", err)
+	}
+	return prs, nil
+}
+
+func (r statsRepo) OpenReviews(ctx context.Context) (repository.OpenReviewSummary, error) {
+	m := repository.OpenReviewSummary{OpenReviewLoadByTeam: map[string]int{}}
+	err := r.q.QueryRow(ctx, `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'OPEN'),
+			COUNT(*) FILTER (WHERE status = 'OPEN' AND cardinality(COALESCE(assigned_reviewers, '{}')) = 0)
+		FROM pull_requests
+	`).Scan(&m.OpenPRs, &m.OpenPRsNoReviewers)
+	if err != nil {
+		return m, fmt.Errorf("failed to count open PRs: %w", err)
+	}
+
+	rows, err := r.q.Query(ctx, `
+		SELECT u.team_name, COUNT(reviewers.reviewer_id)
+		FROM users u
+		LEFT JOIN (
+			SELECT unnest(assigned_reviewers) AS reviewer_id FROM pull_requests WHERE status = 'OPEN'
+		) AS reviewers ON u.user_id = reviewers.reviewer_id
+		WHERE u.team_name IS NOT NULL
+		GROUP BY u.team_name
+		ORDER BY u.team_name
+	`)
+	if err != nil {
+		return m, fmt.Errorf("failed to load team review load: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var team string
+		var load int
+		if err := rows.Scan(&team, &load); err != nil {
+			return m, fmt.Errorf("failed to scan team review load: %w", err)
+		}
+		m.OpenReviewLoadByTeam[team] = load
+	}
+	if err := rows.Err(); err != nil {
+		return m, fmt.Errorf("error during iteration over team review load: %w", err)
+	}
+	return m, nil
+}
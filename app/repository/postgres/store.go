@@ -0,0 +1,81 @@
+// Package postgres implements the repository interfaces on top of a pgx
+// connection pool.
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reviewer-service/app/db"
+	"reviewer-service/app/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// dbtx is satisfied by both the pool and a transaction.
+type dbtx interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type Store struct {
+	pool *pgxpool.Pool
+	q    dbtx
+	inTx bool
+}
+
+var _ repository.Store = (*Store)(nil)
+
+func New(pool *pgxpool.Pool) *Store {
+	return &Store{pool: pool, q: pool}
+}
+
+func (s *Store) Teams() repository.TeamRepo               { return teamRepo{s.q} }
+func (s *Store) Users() repository.UserRepo               { return userRepo{s.q} }
+func (s *Store) PullRequests() repository.PullRequestRepo { return pullRequestRepo{s.q} }
+func (s *Store) Stats() repository.StatsRepo              { return statsRepo{s.q} }
+func (s *Store) Idempotency() repository.IdempotencyRepo  { return idempotencyRepo{s.q} }
+func (s *Store) Tokens() repository.TokenRepo             { return tokenRepo{s.q} }
+func (s *Store) Audit() repository.AuditRepo              { return auditRepo{s.q} }
+
+func (s *Store) InTx(ctx context.Context, fn func(tx repository.Store) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+	return pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		return fn(&Store{pool: s.pool, q: tx, inTx: true})
+	})
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	return s.pool.Ping(ctx)
+}
+
+func (s *Store) CheckSchema(ctx context.Context) error {
+	return db.CheckSchemaVersion(ctx, s.pool)
+}
+
+// Stat exposes the pool statistics for the metrics endpoint.
+func (s *Store) Stat() *pgxpool.Stat {
+	return s.pool.Stat()
+}
+
+// notFound maps pgx.ErrNoRows to repository.ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.ErrNotFound
+	}
+	return err
+}
+
+// uniqueViolation maps a unique key violation to repository.ErrExists.
+func uniqueViolation(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" { // уникальный ключ
+		return fmt.Errorf("%w: %s", repository.ErrExists, pgErr.ConstraintName)
+	}
+	return err
+}
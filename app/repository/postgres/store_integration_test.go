@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"reviewer-service/app/config"
+	"reviewer-service/app/db"
+	"reviewer-service/app/models"
+	"reviewer-service/app/service"
+	"reviewer-service/app/testutils"
+)
+
+func setupStore(t *testing.T) *Store {
+	if err := testutils.LoadTestEnv("../../../.env"); err != nil {
+		t.Fatalf("failed to load env: %v", err)
+	}
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatalf("invalid config: %v", err)
+	}
+	if err := db.Init(cfg.DB); err != nil {
+		t.Fatalf("failed to init DB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.ClearAllTables(); err != nil {
+		t.Fatalf("failed to clear database: %v", err)
+	}
+	return New(db.Pool)
+}
+
+func createTeams(t *testing.T, s *service.Service, teams ...models.Team) {
+	t.Helper()
+	for _, team := range teams {
+		if _, err := s.CreateTeam(context.Background(), team); err != nil {
+			t.Fatalf("create team %s: %v", team.TeamName, err)
+		}
+	}
+}
+
+func TestCandidateTiersTerminatesOnCycle(t *testing.T) {
+	store := setupStore(t)
+	ctx := context.Background()
+	createTeams(t, service.New(store, 0),
+		models.Team{TeamName: "a", Members: []models.TeamMember{{UserID: "u1", Username: "Alice", IsActive: true}}},
+		models.Team{TeamName: "b", ParentTeam: "a", Members: []models.TeamMember{{UserID: "u2", Username: "Bob", IsActive: true}}},
+	)
+	// Written directly: the service refuses to create the cycle.
+	if _, err := db.Pool.Exec(ctx, "UPDATE teams SET parent_team='b' WHERE team_name='a'"); err != nil {
+		t.Fatalf("failed to create cycle: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	tiers, err := store.Teams().CandidateTiers(ctx, "a")
+	if err != nil {
+		t.Fatalf("candidate tiers: %v", err)
+	}
+	if len(tiers) != 2 || tiers[0].TeamName != "a" || tiers[1].TeamName != "b" {
+		t.Fatalf("expected tiers a, b, got %+v", tiers)
+	}
+}
+
+func TestConcurrentReparentingCannotCreateCycle(t *testing.T) {
+	store := setupStore(t)
+	s := service.New(store, 0)
+	createTeams(t, s, models.Team{TeamName: "a"}, models.Team{TeamName: "b"})
+
+	parents := map[string]string{"a": "b", "b": "a"}
+	errs := make(chan error, len(parents))
+	var wg sync.WaitGroup
+	for team, parent := range parents {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.UpdateTeam(context.Background(), models.UpdateTeamRequest{TeamName: team, ParentTeam: &parent}, nil)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	var ok, rejected int
+	for err := range errs {
+		switch {
+		case err == nil:
+			ok++
+		case service.CodeOf(err) == service.CodeValidation:
+			rejected++
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if ok != 1 || rejected != 1 {
+		t.Fatalf("expected one update to win and one to be rejected, got %d and %d", ok, rejected)
+	}
+}
+
+// TestConcurrentMergeAndReassign races a merge against a reassignment that
+// both expect the PR's first version. The row lock makes the loser see the
+// winner's version, so exactly one of them may succeed.
+func TestConcurrentMergeAndReassign(t *testing.T) {
+	store := setupStore(t)
+	s := service.New(store, 0)
+	ctx := context.Background()
+	members := []models.TeamMember{}
+	for i := 1; i <= 6; i++ {
+		members = append(members, models.TeamMember{UserID: fmt.Sprintf("u%d", i), Username: fmt.Sprintf("User %d", i), IsActive: true})
+	}
+	createTeams(t, s, models.Team{TeamName: "backend", Members: members})
+
+	for i := range 20 {
+		prID := fmt.Sprintf("pr-%d", i)
+		created, err := s.CreatePR(ctx, models.CreatePRRequest{PullRequestID: prID, PullRequestName: "Fix", AuthorID: "u1"})
+		if err != nil {
+			t.Fatalf("create PR: %v", err)
+		}
+		cond := service.Precondition{created.PR.Version}
+
+		var wg sync.WaitGroup
+		var mergeErr, reassignErr error
+		wg.Go(func() { _, mergeErr = s.MergePR(ctx, prID, cond) })
+		wg.Go(func() { _, reassignErr = s.ReassignPR(ctx, prID, created.PR.AssignedReviewers[0], cond) })
+		wg.Wait()
+
+		if (mergeErr == nil) == (reassignErr == nil) {
+			t.Fatalf("%s: expected exactly one winner, got merge %v and reassign %v", prID, mergeErr, reassignErr)
+		}
+		for _, err := range []error{mergeErr, reassignErr} {
+			if code := service.CodeOf(err); err != nil && code != service.CodePreconditionFailed && code != service.CodePRMerged {
+				t.Fatalf("%s: unexpected error: %v", prID, err)
+			}
+		}
+
+		pr, err := store.PullRequests().Get(ctx, prID)
+		if err != nil {
+			t.Fatalf("get PR: %v", err)
+		}
+		if pr.Version != created.PR.Version+1 {
+			t.Fatalf("%s: expected one version bump, got version %d", prID, pr.Version)
+		}
+		if merged := pr.Status == "MERGED"; merged != (mergeErr == nil) {
+			t.Fatalf("%s: status %s does not match merge result %v", prID, pr.Status, mergeErr)
+		}
+	}
+}
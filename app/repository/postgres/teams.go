@@ -0,0 +1,266 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type teamRepo struct {
+	q dbtx
+}
+
+const teamInfoColumns = `team_name, COALESCE(parent_team, ''), kind,
+	reviewer_count, review_sla_hours, require_senior, add_junior_reviewer, version`
+
+func scanTeamInfo(row pgx.Row) (repository.TeamInfo, error) {
+	var t repository.TeamInfo
+	var policy models.TeamPolicy
+	if err := row.Scan(
+		&t.Name, &t.Parent, &t.Kind,
+		&policy.ReviewerCount, &policy.ReviewSLAHours, &policy.RequireSenior, &policy.AddJuniorReviewer,
+		&t.Version,
+	); err != nil {
+		return t, err
+	}
+	if policy != (models.TeamPolicy{}) {
+		t.Policy = &policy
+	}
+	return t, nil
+}
+
+func collectTeamInfo(rows pgx.Rows, err error) ([]repository.TeamInfo, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var teams []repository.TeamInfo
+	for rows.Next() {
+		t, err := scanTeamInfo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan team: %w", err)
+		}
+		teams = append(teams, t)
+	}
+	return teams, rows.Err()
+}
+
+func (r teamRepo) Create(ctx context.Context, team models.Team) error {
+	var policy models.TeamPolicy
+	if team.Policy != nil {
+		policy = *team.Policy
+	}
+	_, err := r.q.Exec(ctx, `
+		INSERT INTO teams(team_name, parent_team, kind, reviewer_count, review_sla_hours, require_senior, add_junior_reviewer)
+		VALUES($1, NULLIF($2, ''), $3, $4, $5, $6, $7)
+	`, team.TeamName, team.ParentTeam, team.Kind,
+		policy.ReviewerCount, policy.ReviewSLAHours, policy.RequireSenior, policy.AddJuniorReviewer)
+	if err != nil {
+		return uniqueViolation(err)
+	}
+	return r.saveFallbacks(ctx, team.TeamName, team.FallbackTeams)
+}
+
+func (r teamRepo) saveFallbacks(ctx context.Context, teamName string, fallbacks []string) error {
+	if len(fallbacks) == 0 {
+		return nil
+	}
+	_, err := r.q.Exec(ctx, `
+		INSERT INTO team_fallbacks(team_name, fallback_team, priority)
+		SELECT $1, f.team_name, f.priority
+		FROM unnest($2::text[]) WITH ORDINALITY AS f(team_name, priority)
+	`, teamName, fallbacks)
+	return err
+}
+
+func (r teamRepo) Get(ctx context.Context, name string) (models.Team, error) {
+	info, err := scanTeamInfo(r.q.QueryRow(ctx, `
+		SELECT `+teamInfoColumns+`
+		FROM teams WHERE team_name=$1
+	`, name))
+	if err != nil {
+		return models.Team{}, notFound(err)
+	}
+
+	members, err := userRepo{r.q}.MembersOf(ctx, []string{name})
+	if err != nil {
+		return models.Team{}, err
+	}
+
+	rows, err := r.q.Query(ctx, `
+		SELECT fallback_team FROM team_fallbacks WHERE team_name=$1 ORDER BY priority
+	`, name)
+	if err != nil {
+		return models.Team{}, err
+	}
+	fallbacks, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return models.Team{}, err
+	}
+
+	team := models.Team{
+		TeamName:      info.Name,
+		Members:       members[name],
+		FallbackTeams: fallbacks,
+		ParentTeam:    info.Parent,
+		Kind:          info.Kind,
+		Policy:        info.Policy,
+		Version:       info.Version,
+	}
+	if team.Members == nil {
+		team.Members = []models.TeamMember{}
+	}
+	return team, nil
+}
+
+func (r teamRepo) Lock(ctx context.Context, name string) (repository.TeamInfo, error) {
+	info, err := scanTeamInfo(r.q.QueryRow(ctx, `
+		SELECT `+teamInfoColumns+`
+		FROM teams WHERE team_name=$1 FOR UPDATE
+	`, name))
+	return info, notFound(err)
+}
+
+// LockHierarchy takes a transaction-level advisory lock, so that two updates
+// cannot each pass the cycle check against the other's old parent.
+func (r teamRepo) LockHierarchy(ctx context.Context) error {
+	_, err := r.q.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext('teams.parent_team'))")
+	return err
+}
+
+func (r teamRepo) Update(ctx context.Context, req models.UpdateTeamRequest) error {
+	if req.Kind == nil {
+		return errors.New("team update requires kind")
+	}
+	if _, err := r.q.Exec(ctx, "UPDATE teams SET kind=$1, version=version+1 WHERE team_name=$2", *req.Kind, req.TeamName); err != nil {
+		return err
+	}
+	if req.ParentTeam != nil {
+		if _, err := r.q.Exec(ctx, `
+			UPDATE teams SET parent_team=NULLIF($1, '') WHERE team_name=$2
+		`, *req.ParentTeam, req.TeamName); err != nil {
+			return err
+		}
+	}
+	if req.Policy != nil {
+		if _, err := r.q.Exec(ctx, `
+			UPDATE teams
+			SET reviewer_count=$1, review_sla_hours=$2, require_senior=$3, add_junior_reviewer=$4
+			WHERE team_name=$5
+		`, req.Policy.ReviewerCount, req.Policy.ReviewSLAHours, req.Policy.RequireSenior, req.Policy.AddJuniorReviewer,
+			req.TeamName); err != nil {
+			return err
+		}
+	}
+	if req.FallbackTeams != nil {
+		if _, err := r.q.Exec(ctx, "DELETE FROM team_fallbacks WHERE team_name=$1", req.TeamName); err != nil {
+			return err
+		}
+		return r.saveFallbacks(ctx, req.TeamName, *req.FallbackTeams)
+	}
+	return nil
+}
+
+func (r teamRepo) List(ctx context.Context) ([]repository.TeamInfo, error) {
+	teams, err := collectTeamInfo(r.q.Query(ctx, `
+		SELECT `+teamInfoColumns+`
+		FROM teams
+		ORDER BY team_name
+	`))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load teams: %w", err)
+	}
+	return teams, nil
+}
+
+func (r teamRepo) Chain(ctx context.Context, name string) ([]repository.TeamInfo, error) {
+	chain, err := collectTeamInfo(r.q.Query(ctx, `
+		WITH RECURSIVE chain AS (
+			SELECT teams.*, 0 AS depth
+			FROM teams WHERE team_name = $1
+			UNION ALL
+			SELECT t.*, c.depth + 1
+			FROM teams t JOIN chain c ON t.team_name = c.parent_team
+			WHERE c.depth < $2
+		)
+		SELECT `+teamInfoColumns+`
+		FROM chain
+		ORDER BY depth
+	`, name, repository.MaxHierarchyDepth))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load team chain: %w", err)
+	}
+	return chain, nil
+}
+
+func (r teamRepo) FirstMissing(ctx context.Context, names []string) (string, error) {
+	var missing string
+	err := r.q.QueryRow(ctx, `
+		SELECT n FROM unnest($1::text[]) AS n
+		WHERE NOT EXISTS (SELECT 1 FROM teams WHERE team_name = n)
+		LIMIT 1
+	`, names).Scan(&missing)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return missing, err
+}
+
+func (r teamRepo) CandidateTiers(ctx context.Context, name string) ([]repository.CandidateTier, error) {
+	rows, err := r.q.Query(ctx, `
+		WITH RECURSIVE ancestors(team_name, depth) AS (
+			SELECT parent_team, 1 FROM teams WHERE team_name = $1 AND parent_team IS NOT NULL
+			UNION ALL
+			SELECT t.parent_team, a.depth + 1
+			FROM teams t JOIN ancestors a ON t.team_name = a.team_name
+			WHERE t.parent_team IS NOT NULL AND a.depth < $2
+		), subtrees(team_name, depth, level) AS (
+			SELECT team_name, depth, 0 FROM ancestors
+			UNION ALL
+			SELECT t.team_name, s.depth, s.level + 1
+			FROM teams t JOIN subtrees s ON t.parent_team = s.team_name
+			WHERE s.level < $2
+		), tiers(team_name, source, rank, priority) AS (
+			SELECT $1::text, 'home', 0, 0
+			UNION ALL
+			SELECT fallback_team, 'fallback', 1, priority FROM team_fallbacks WHERE team_name = $1
+			UNION ALL
+			SELECT team_name, 'hierarchy', 2, depth FROM subtrees
+		)
+		SELECT t.team_name, t.source, u.user_id, u.seniority, u.is_active
+		FROM (
+			SELECT DISTINCT ON (team_name) team_name, source, rank, priority
+			FROM tiers
+			ORDER BY team_name, rank, priority
+		) t
+		JOIN users u ON u.team_name = t.team_name
+		ORDER BY t.rank, t.priority, t.team_name, u.user_id
+	`, name, repository.MaxHierarchyDepth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var tiers []repository.CandidateTier
+	for rows.Next() {
+		var team, source string
+		var c repository.Candidate
+		if err := rows.Scan(&team, &source, &c.UserID, &c.Seniority, &c.IsActive); err != nil {
+			return nil, fmt.Errorf("failed to scan candidate: %w", err)
+		}
+		if len(tiers) == 0 || tiers[len(tiers)-1].TeamName != team {
+			tiers = append(tiers, repository.CandidateTier{TeamName: team, Source: source, CrossTeam: team != name})
+		}
+		last := &tiers[len(tiers)-1]
+		last.Candidates = append(last.Candidates, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during iteration over candidates: %w", err)
+	}
+	return tiers, nil
+}
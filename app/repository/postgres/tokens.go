@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+	"reviewer-service/app/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type tokenRepo struct {
+	q dbtx
+}
+
+func (r tokenRepo) Create(ctx context.Context, token models.APIToken, hash string) error {
+	_, err := r.q.Exec(ctx, `
+		INSERT INTO api_tokens(token_id, name, role, team_name, token_hash, created_at)
+		VALUES($1, $2, $3, NULLIF($4, ''), $5, $6)
+	`, token.TokenID, token.Name, token.Role, token.TeamName, hash, token.CreatedAt)
+	return uniqueViolation(err)
+}
+
+const tokenColumns = `token_id, name, role, COALESCE(team_name, ''), created_at, revoked_at`
+
+func scanToken(row pgx.Row) (models.APIToken, error) {
+	var t models.APIToken
+	err := row.Scan(&t.TokenID, &t.Name, &t.Role, &t.TeamName, &t.CreatedAt, &t.RevokedAt)
+	return t, notFound(err)
+}
+
+func (r tokenRepo) GetByHash(ctx context.Context, hash string) (models.APIToken, error) {
+	return scanToken(r.q.QueryRow(ctx, `SELECT `+tokenColumns+` FROM api_tokens WHERE token_hash=$1`, hash))
+}
+
+func (r tokenRepo) Revoke(ctx context.Context, tokenID string) (models.APIToken, error) {
+	return scanToken(r.q.QueryRow(ctx, `
+		UPDATE api_tokens SET revoked_at=COALESCE(revoked_at, NOW())
+		WHERE token_id=$1
+		RETURNING `+tokenColumns, tokenID))
+}
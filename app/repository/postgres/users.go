@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type userRepo struct {
+	q dbtx
+}
+
+// touchTeamsOf increments the version of the teams userIDs belong to.
+func (r userRepo) touchTeamsOf(ctx context.Context, userIDs []string) error {
+	_, err := r.q.Exec(ctx, `
+		UPDATE teams SET version=version+1
+		WHERE team_name IN (SELECT team_name FROM users WHERE user_id = ANY($1))
+	`, userIDs)
+	return err
+}
+
+func (r userRepo) Upsert(ctx context.Context, teamName string, m models.TeamMember) error {
+	// The team the user leaves loses a member.
+	if _, err := r.q.Exec(ctx, `
+		UPDATE teams SET version=version+1
+		WHERE team_name = (SELECT team_name FROM users WHERE user_id=$1) AND team_name <> $2
+	`, m.UserID, teamName); err != nil {
+		return err
+	}
+	_, err := r.q.Exec(ctx, `
+		INSERT INTO users(user_id, username, team_name, is_active, seniority)
+		VALUES($1,$2,$3,$4,$5)
+		ON CONFLICT (user_id) DO UPDATE SET username=EXCLUDED.username, team_name=EXCLUDED.team_name,
+			is_active=EXCLUDED.is_active, seniority=EXCLUDED.seniority
+	`, m.UserID, m.Username, teamName, m.IsActive, m.Seniority)
+	return err
+}
+
+func (r userRepo) Get(ctx context.Context, userID string) (models.User, error) {
+	user := models.User{UserID: userID}
+	err := r.q.QueryRow(ctx, `
+		SELECT username, team_name, is_active, seniority FROM users WHERE user_id=$1
+	`, userID).Scan(&user.Username, &user.TeamName, &user.IsActive, &user.Seniority)
+	return user, notFound(err)
+}
+
+func (r userRepo) SetActive(ctx context.Context, userID string, active bool) error {
+	tag, err := r.q.Exec(ctx, "UPDATE users SET is_active=$1 WHERE user_id=$2", active, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return r.touchTeamsOf(ctx, []string{userID})
+}
+
+func (r userRepo) SetSeniority(ctx context.Context, userID, seniority string) error {
+	tag, err := r.q.Exec(ctx, "UPDATE users SET seniority=$1 WHERE user_id=$2", seniority, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return r.touchTeamsOf(ctx, []string{userID})
+}
+
+func (r userRepo) MembersOf(ctx context.Context, teams []string) (map[string][]models.TeamMember, error) {
+	rows, err := r.q.Query(ctx, `
+		SELECT team_name, user_id, username, is_active, seniority FROM users
+		WHERE team_name = ANY($1)
+		ORDER BY user_id
+	`, teams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load members: %w", err)
+	}
+	defer rows.Close()
+
+	members := map[string][]models.TeamMember{}
+	for rows.Next() {
+		var team string
+		var m models.TeamMember
+		if err := rows.Scan(&team, &m.UserID, &m.Username, &m.IsActive, &m.Seniority); err != nil {
+			return nil, fmt.Errorf("failed to scan member: %w", err)
+		}
+		members[team] = append(members[team], m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during iteration over members: %w", err)
+	}
+	return members, nil
+}
+
+func (r userRepo) Deactivate(ctx context.Context, userIDs []string) ([]string, error) {
+	rows, err := r.q.Query(ctx, `
+		UPDATE users SET is_active = FALSE WHERE user_id = ANY($1) AND is_active = TRUE RETURNING user_id
+	`, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute deactivate query: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("error during iteration over deactivated users: %w", err)
+	}
+	if err := r.touchTeamsOf(ctx, ids); err != nil {
+		return nil, fmt.Errorf("failed to update team versions: %w", err)
+	}
+	return ids, nil
+}
+
+func (r userRepo) AnySenior(ctx context.Context, userIDs []string) (bool, error) {
+	var found bool
+	err := r.q.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM users WHERE user_id = ANY($1) AND seniority = 'senior')
+	`, userIDs).Scan(&found)
+	return found, err
+}
+
+func (r userRepo) RandomActiveTeammate(ctx context.Context, team, exclude string) (string, error) {
+	var userID string
+	err := r.q.QueryRow(ctx, `
+		SELECT user_id FROM users
+		WHERE team_name = $1 AND is_active = TRUE AND user_id != $2
+		ORDER BY RANDOM() LIMIT 1 -- Выбираем случайного активного пользователя
+	`, team, exclude).Scan(&userID)
+	return userID, notFound(err)
+}
@@ -0,0 +1,267 @@
+// Package repository defines the storage interfaces the service layer depends
+// on. The postgres subpackage implements them with pgx, the memory subpackage
+// keeps everything in process so that the service can be exercised without a
+// database.
+package repository
+
+import (
+	"context"
+	"errors"
+	"reviewer-service/app/models"
+	"time"
+)
+
+// MaxHierarchyDepth bounds walks over teams.parent_team.
+const MaxHierarchyDepth = 32
+
+var (
+	// ErrNotFound is returned when the requested row does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrExists is returned when a row with the same key already exists.
+	ErrExists = errors.New("already exists")
+)
+
+// Store groups the repositories of one backend.
+type Store interface {
+	Teams() TeamRepo
+	Users() UserRepo
+	PullRequests() PullRequestRepo
+	Stats() StatsRepo
+	Idempotency() IdempotencyRepo
+	Tokens() TokenRepo
+	Audit() AuditRepo
+
+	// InTx runs fn with a Store whose repositories share one transaction. The
+	// transaction commits when fn returns nil and rolls back otherwise.
+	// Calling InTx on a transactional Store runs fn in the same transaction.
+	InTx(ctx context.Context, fn func(tx Store) error) error
+
+	// Ping checks that the backend is reachable.
+	Ping(ctx context.Context) error
+	// CheckSchema verifies that the schema the code expects is in place.
+	CheckSchema(ctx context.Context) error
+}
+
+// TeamInfo is a team's own row without members.
+type TeamInfo struct {
+	Name    string
+	Parent  string
+	Kind    string
+	Policy  *models.TeamPolicy
+	Version int64
+}
+
+// Candidate is a possible reviewer as seen by reviewer selection.
+type Candidate struct {
+	UserID    string
+	Seniority string
+	IsActive  bool
+}
+
+// CandidateTier groups the members of one team. The first tier is the home
+// team, followed by its fallback teams in priority order and then by the rest
+// of every ancestor's subtree, nearest ancestor first.
+type CandidateTier struct {
+	TeamName   string
+	Source     string
+	CrossTeam  bool
+	Candidates []Candidate
+}
+
+type TeamRepo interface {
+	// Create stores the team settings and fallback teams; members are saved
+	// through UserRepo.Upsert. Returns ErrExists when the name is taken.
+	Create(ctx context.Context, team models.Team) error
+	// Get returns the team with its members ordered by user ID and its
+	// fallback teams in priority order.
+	Get(ctx context.Context, name string) (models.Team, error)
+	// Lock returns the team's settings and keeps the row locked until the
+	// surrounding transaction ends.
+	Lock(ctx context.Context, name string) (TeamInfo, error)
+	// LockHierarchy serializes changes of parent teams until the surrounding
+	// transaction ends.
+	LockHierarchy(ctx context.Context) error
+	// Update applies the non-nil fields of req and increments the team
+	// version; req.Kind must be set.
+	Update(ctx context.Context, req models.UpdateTeamRequest) error
+	// List returns every team ordered by name.
+	List(ctx context.Context) ([]TeamInfo, error)
+	// Chain returns the team followed by its ancestors, nearest first.
+	Chain(ctx context.Context, name string) ([]TeamInfo, error)
+	// FirstMissing returns the first of names that is not a team, or "".
+	FirstMissing(ctx context.Context, names []string) (string, error)
+	// CandidateTiers returns the reviewer search order for the team.
+	CandidateTiers(ctx context.Context, name string) ([]CandidateTier, error)
+}
+
+// UserRepo changes users. Every change that alters a team's member list
+// (including a member's activity or seniority) increments that team's
+// version, except for adding members to a team being created.
+type UserRepo interface {
+	// Upsert creates the user or moves it to teamName with the given settings.
+	Upsert(ctx context.Context, teamName string, member models.TeamMember) error
+	Get(ctx context.Context, userID string) (models.User, error)
+	// SetActive and SetSeniority update the user and then the team version in
+	// separate statements; call them inside InTx.
+	SetActive(ctx context.Context, userID string, active bool) error
+	SetSeniority(ctx context.Context, userID, seniority string) error
+	// MembersOf returns the members of each of the teams ordered by user ID.
+	MembersOf(ctx context.Context, teams []string) (map[string][]models.TeamMember, error)
+	// Deactivate marks the active users among userIDs inactive and returns them.
+	Deactivate(ctx context.Context, userIDs []string) ([]string, error)
+	// AnySenior reports whether one of userIDs is a senior.
+	AnySenior(ctx context.Context, userIDs []string) (bool, error)
+	// RandomActiveTeammate picks an active member of team other than exclude;
+	// ErrNotFound when there is none.
+	RandomActiveTeammate(ctx context.Context, team, exclude string) (string, error)
+}
+
+// PullRequestFilter selects PRs by status, author, the author's team and
+// creation time. Empty fields do not filter.
+type PullRequestFilter struct {
+	Status   string
+	AuthorID string
+	Team     string
+	From     *time.Time
+	To       *time.Time
+}
+
+type PullRequestRepo interface {
+	// Create stores an OPEN PR and opens review assignments for its
+	// reviewers. Returns ErrExists when the ID is taken.
+	Create(ctx context.Context, pr models.PullRequest) error
+	Get(ctx context.Context, prID string) (models.PullRequest, error)
+	// Lock returns the PR and keeps its row locked until the surrounding
+	// transaction ends, so that concurrent merges and reassignments of the
+	// same PR are applied one after another.
+	Lock(ctx context.Context, prID string) (models.PullRequest, error)
+	// Merge marks the PR as merged now and increments its version.
+	Merge(ctx context.Context, prID string) error
+	// SetReviewers replaces the assigned reviewers, closing the review
+	// assignments of removed reviewers and opening ones for added reviewers.
+	// The PR version is incremented and the new one returned; ErrNotFound
+	// when there is no such PR.
+	SetReviewers(ctx context.Context, prID string, reviewers []string) (int64, error)
+	// SubmitReview records a verdict on the reviewer's open assignment;
+	// ErrNotFound when the reviewer is not assigned.
+	SubmitReview(ctx context.Context, prID, reviewerID, verdict string) (models.Review, error)
+	// ListByReviewer returns the PRs the user is assigned to.
+	ListByReviewer(ctx context.Context, userID string) ([]models.PullRequestShort, error)
+	// OpenByAuthors returns the OPEN PRs written by any of authorIDs.
+	OpenByAuthors(ctx context.Context, authorIDs []string) ([]models.PullRequestShort, error)
+	// List calls fn for every matching PR ordered by creation time, stopping
+	// at the first error.
+	List(ctx context.Context, filter PullRequestFilter, fn func(models.PullRequest) error) error
+}
+
+// AssignmentStatsFilter narrows which assignments are counted (by PR status
+// and creation time) and which users are listed (by team and activity).
+type AssignmentStatsFilter struct {
+	Team       string
+	Status     string
+	From       *time.Time
+	To         *time.Time
+	ActiveOnly bool
+}
+
+// ReviewLoad is one active team member's review counts for the fairness
+// report: PRs created in the window and PRs still open.
+type ReviewLoad struct {
+	TeamName string
+	UserID   string
+	Assigned int
+	Open     int
+}
+
+// HistoricalPR is a PR as replayed by the assignment simulation.
+type HistoricalPR struct {
+	AuthorID  string
+	TeamName  string
+	Reviewers []string
+	CreatedAt time.Time
+	MergedAt  *time.Time
+}
+
+// OpenReviewSummary holds the domain gauges exported as metrics.
+type OpenReviewSummary struct {
+	OpenPRs              int
+	OpenPRsNoReviewers   int
+	OpenReviewLoadByTeam map[string]int
+}
+
+type StatsRepo interface {
+	// Assignments calls fn for every listed user, most assigned first.
+	Assignments(ctx context.Context, filter AssignmentStatsFilter, fn func(models.UserAssignmentStats) error) error
+	// TeamAssignments returns member and assignment counts of each team that
+	// has members; the hierarchy fields are left empty.
+	TeamAssignments(ctx context.Context, teams []string) (map[string]models.TeamAssignmentStats, error)
+	MergeTimes(ctx context.Context, from, to *time.Time) (models.MergeTimeStats, error)
+	ReviewLatency(ctx context.Context, from, to *time.Time) ([]models.ReviewLatencyStats, error)
+	WeeklyTrends(ctx context.Context, from, to *time.Time) ([]models.WeeklyTrend, error)
+	// ReviewLoads returns active team members ordered by team and user ID.
+	ReviewLoads(ctx context.Context, from, to *time.Time, team string) ([]ReviewLoad, error)
+	// History returns PRs created in [from, to) ordered by creation time.
+	History(ctx context.Context, from, to time.Time) ([]HistoricalPR, error)
+	OpenReviews(ctx context.Context) (OpenReviewSummary, error)
+}
+
+// IdempotencyRecord is a stored Idempotency-Key: the hash of the request that
+// first used it and, once that request finished, its response.
+type IdempotencyRecord struct {
+	Key         string
+	RequestHash string
+	// Done is false while the first request is still being handled.
+	Done        bool
+	Status      int
+	ContentType string
+	ETag        string
+	Body        []byte
+	CreatedAt   time.Time
+}
+
+type IdempotencyRepo interface {
+	// Claim records a pending key for requestHash. Records created before
+	// expiredBefore are replaced. When the key is already taken, the existing
+	// record is returned with claimed set to false.
+	Claim(ctx context.Context, key, requestHash string, expiredBefore time.Time) (rec IdempotencyRecord, claimed bool, err error)
+	// Complete stores the response of a claimed key and marks it done.
+	Complete(ctx context.Context, rec IdempotencyRecord) error
+	// Release forgets a pending key so that the request can be retried.
+	Release(ctx context.Context, key string) error
+	// Purge deletes records created before t and returns how many there were.
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+type TokenRepo interface {
+	// Create stores the token under the hash of its secret. Returns ErrExists
+	// when the ID or hash is taken.
+	Create(ctx context.Context, token models.APIToken, hash string) error
+	// GetByHash returns the token whose secret has the given hash, revoked or
+	// not; ErrNotFound when there is none.
+	GetByHash(ctx context.Context, hash string) (models.APIToken, error)
+	// Revoke marks the token revoked now unless it already is and returns it;
+	// ErrNotFound when there is no such token.
+	Revoke(ctx context.Context, tokenID string) (models.APIToken, error)
+}
+
+// AuditFilter selects audit entries. Actor matches the token name or ID and
+// Target matches any of an entry's comma-separated targets; empty fields do
+// not filter. Only entries with an ID below BeforeID are returned when it is
+// set.
+type AuditFilter struct {
+	Actor    string
+	Action   string
+	Target   string
+	Result   string
+	From     *time.Time
+	To       *time.Time
+	BeforeID int64
+	Limit    int
+}
+
+type AuditRepo interface {
+	// Append stores the entry; ID and CreatedAt are assigned by the store.
+	Append(ctx context.Context, entry models.AuditEntry) error
+	// List returns at most filter.Limit matching entries, newest first.
+	List(ctx context.Context, filter AuditFilter) ([]models.AuditEntry, error)
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"slices"
+)
+
+var (
+	errAuthorNotFound = newError(CodeNotFound, "author or team not found")
+	errPRNotFound     = newError(CodeNotFound, "PR not found")
+	errNotAssigned    = newError(CodeNotAssigned, "reviewer is not assigned to this PR")
+)
+
+// assignmentPlan is the outcome of reviewer selection before anything is
+// written, shared by the create, reassign and explain operations.
+type assignmentPlan struct {
+	TeamName  string
+	AuthorID  string
+	Policy    models.EffectivePolicy
+	Selection reviewerSelection
+}
+
+type reassignPlan struct {
+	assignmentPlan
+	Assigned []string
+	Index    int
+}
+
+func (p *assignmentPlan) explanation(operation, prID string) models.AssignmentExplanation {
+	return models.AssignmentExplanation{
+		Operation:     operation,
+		PullRequestID: prID,
+		AuthorID:      p.AuthorID,
+		TeamName:      p.TeamName,
+		Policy:        p.Policy,
+		Candidates:    p.Selection.Trace,
+		Selected:      p.Selection.Reviewers,
+		Warnings:      p.Selection.Warnings,
+	}
+}
+
+func loadUserTeam(ctx context.Context, users repository.UserRepo, userID string) (string, error) {
+	user, err := users.Get(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return "", errAuthorNotFound
+	}
+	return user.TeamName, err
+}
+
+func planCreate(ctx context.Context, store repository.Store, authorID string) (assignmentPlan, error) {
+	plan := assignmentPlan{AuthorID: authorID}
+
+	teamName, err := loadUserTeam(ctx, store.Users(), authorID)
+	if err != nil {
+		return plan, err
+	}
+	plan.TeamName = teamName
+
+	if plan.Policy, err = loadEffectivePolicy(ctx, store.Teams(), teamName); err != nil {
+		return plan, err
+	}
+
+	tiers, err := store.Teams().CandidateTiers(ctx, teamName)
+	if err != nil {
+		return plan, err
+	}
+
+	plan.Selection = pickReviewers(tiers, map[string]string{authorID: reasonAuthor}, rulesFromPolicy(plan.Policy))
+	return plan, nil
+}
+
+// planReassign picks a replacement from the old reviewer's team. A senior is
+// required only when the author's team asks for one and no senior remains
+// among the other reviewers. With lock set the PR row stays locked until the
+// surrounding transaction ends; read-only callers leave it unset. The PR must
+// match cond.
+func planReassign(ctx context.Context, store repository.Store, prID, oldReviewerID string, cond Precondition, lock bool) (reassignPlan, error) {
+	var plan reassignPlan
+	load := store.PullRequests().Get
+	if lock {
+		load = store.PullRequests().Lock
+	}
+	pr, err := load(ctx, prID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return plan, errPRNotFound
+	}
+	if err != nil {
+		return plan, fmt.Errorf("failed to load PR: %w", err)
+	}
+	if err := cond.check(pr.Version); err != nil {
+		return plan, err
+	}
+	plan.AuthorID, plan.Assigned = pr.AuthorID, pr.AssignedReviewers
+
+	if pr.Status == "MERGED" {
+		return plan, newError(CodePRMerged, "cannot reassign on merged PR")
+	}
+
+	plan.Index = slices.Index(plan.Assigned, oldReviewerID)
+	if plan.Index < 0 {
+		return plan, errNotAssigned
+	}
+
+	if plan.TeamName, err = loadUserTeam(ctx, store.Users(), oldReviewerID); err != nil {
+		return plan, err
+	}
+	authorTeam, err := loadUserTeam(ctx, store.Users(), plan.AuthorID)
+	if err != nil {
+		return plan, err
+	}
+	if plan.Policy, err = loadEffectivePolicy(ctx, store.Teams(), authorTeam); err != nil {
+		return plan, err
+	}
+
+	tiers, err := store.Teams().CandidateTiers(ctx, plan.TeamName)
+	if err != nil {
+		return plan, err
+	}
+
+	rules := selectionRules{Count: 1}
+	if plan.Policy.RequireSenior {
+		remaining := slices.Delete(slices.Clone(plan.Assigned), plan.Index, plan.Index+1)
+		hasSenior, err := store.Users().AnySenior(ctx, remaining)
+		if err != nil {
+			return plan, fmt.Errorf("failed to check remaining reviewers: %w", err)
+		}
+		rules.RequireSenior = !hasSenior
+	}
+
+	exclude := map[string]string{plan.AuthorID: reasonAuthor}
+	for _, uid := range plan.Assigned {
+		exclude[uid] = reasonAlreadyAssigned
+	}
+	exclude[oldReviewerID] = reasonReplaced
+	plan.Selection = pickReviewers(tiers, exclude, rules)
+	return plan, nil
+}
+
+// ExplainAssignment runs reviewer selection without writing anything. With
+// OldReviewerID it explains a reassignment of the given PR, otherwise the
+// creation of a PR by AuthorID (or by the given PR's author).
+func (s *Service) ExplainAssignment(ctx context.Context, req models.ExplainAssignmentRequest) (models.AssignmentExplanation, error) {
+	switch {
+	case req.OldReviewerID != "":
+		plan, err := planReassign(ctx, s.store, req.PullRequestID, req.OldReviewerID, nil, false)
+		if err != nil {
+			return models.AssignmentExplanation{}, err
+		}
+		return plan.explanation("reassign", req.PullRequestID), nil
+	case req.AuthorID != "" || req.PullRequestID != "":
+		authorID := req.AuthorID
+		if authorID == "" {
+			pr, err := s.store.PullRequests().Get(ctx, req.PullRequestID)
+			if errors.Is(err, repository.ErrNotFound) {
+				err = errPRNotFound
+			}
+			if err != nil {
+				return models.AssignmentExplanation{}, err
+			}
+			authorID = pr.AuthorID
+		}
+		plan, err := planCreate(ctx, s.store, authorID)
+		if err != nil {
+			return models.AssignmentExplanation{}, err
+		}
+		return plan.explanation("create", req.PullRequestID), nil
+	default:
+		return models.AssignmentExplanation{}, invalid("author_id or pull_request_id required")
+	}
+}
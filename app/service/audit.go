@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reviewer-service/app/auth"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"time"
+	"unicode/utf8"
+)
+
+// Audited actions.
+const (
+	ActionTeamCreate      = "team.create"
+	ActionTeamUpdate      = "team.update"
+	ActionUserSetActive   = "user.set_active"
+	ActionUsersDeactivate = "users.deactivate"
+	ActionPRMerge         = "pull_request.merge"
+	ActionPRReassign      = "pull_request.reassign"
+	ActionTokenIssue      = "token.issue"
+	ActionTokenRevoke     = "token.revoke"
+)
+
+const (
+	// maxAuditPayload bounds the request summary kept with an entry.
+	maxAuditPayload = 1024
+	// auditWriteTimeout bounds writing the entry of a failed attempt, which
+	// happens even when the caller has gone away.
+	auditWriteTimeout = 5 * time.Second
+)
+
+// recordAudit appends the entry of a successful action through tx. Operations
+// call it as the last step of their transaction, so that the action and its
+// entry commit or roll back together.
+func recordAudit(ctx context.Context, tx repository.Store, action, target string, payload any) error {
+	if err := tx.Audit().Append(ctx, newAuditEntry(ctx, action, target, payload, nil)); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	return nil
+}
+
+// auditFailure records a failed attempt at action when *errp is set. It is
+// deferred by the audited operations and runs after their transaction has
+// rolled back, so failing to write the entry is only logged.
+func (s *Service) auditFailure(ctx context.Context, action, target string, payload any, errp *error) {
+	if *errp == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditWriteTimeout)
+	defer cancel()
+	if err := s.store.Audit().Append(ctx, newAuditEntry(ctx, action, target, payload, *errp)); err != nil {
+		log.Printf("failed to write audit entry %s %s: %v", action, target, err)
+	}
+}
+
+func newAuditEntry(ctx context.Context, action, target string, payload any, err error) models.AuditEntry {
+	entry := models.AuditEntry{
+		Actor:   "anonymous",
+		Action:  action,
+		Target:  target,
+		Payload: summarizePayload(payload),
+		Result:  auditResult(err),
+	}
+	if p, ok := auth.FromContext(ctx); ok {
+		entry.Actor, entry.TokenID, entry.Role = p.Name, p.TokenID, string(p.Role)
+	}
+	return entry
+}
+
+// auditPayload summarizes PR operations, whose target already names the PR.
+type auditPayload struct {
+	OldReviewerID string       `json:"old_reviewer_id,omitempty"`
+	IfMatch       Precondition `json:"if_match,omitempty"`
+}
+
+func auditResult(err error) string {
+	switch {
+	case err == nil:
+		return "OK"
+	case CodeOf(err) != "":
+		return string(CodeOf(err))
+	case errors.Is(err, context.DeadlineExceeded):
+		return "TIMEOUT"
+	case errors.Is(err, context.Canceled):
+		return "CANCELED"
+	default:
+		return "INTERNAL"
+	}
+}
+
+// summarizePayload renders payload as JSON cut to maxAuditPayload bytes.
+func summarizePayload(payload any) string {
+	if payload == nil {
+		return ""
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return ""
+	}
+	if len(data) <= maxAuditPayload {
+		return string(data)
+	}
+	cut := maxAuditPayload
+	for cut > 0 && !utf8.RuneStart(data[cut]) {
+		cut--
+	}
+	return string(data[:cut]) + "..."
+}
+
+// ListAudit returns audit entries matching filter, newest first.
+func (s *Service) ListAudit(ctx context.Context, filter repository.AuditFilter) ([]models.AuditEntry, error) {
+	return s.store.Audit().List(ctx, filter)
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reviewer-service/app/models"
+	"slices"
+	"time"
+)
+
+// computeFairness describes how evenly loads are spread. Gini is 0 for a
+// perfectly even distribution and approaches 1 when one user takes everything.
+// MaxMinRatio is nil when somebody has no load at all.
+func computeFairness(loads []int) models.FairnessMetrics {
+	var m models.FairnessMetrics
+	if len(loads) == 0 {
+		return m
+	}
+
+	sorted := slices.Clone(loads)
+	slices.Sort(sorted)
+	m.Min, m.Max = sorted[0], sorted[len(sorted)-1]
+
+	n := float64(len(sorted))
+	var sum, weighted float64
+	for i, v := range sorted {
+		sum += float64(v)
+		weighted += float64(i+1) * float64(v)
+	}
+	m.Mean = sum / n
+
+	var variance float64
+	for _, v := range sorted {
+		d := float64(v) - m.Mean
+		variance += d * d
+	}
+	m.StdDev = math.Sqrt(variance / n)
+
+	if sum > 0 {
+		m.Gini = (2*weighted)/(n*sum) - (n+1)/n
+	}
+	if m.Min > 0 {
+		ratio := float64(m.Max) / float64(m.Min)
+		m.MaxMinRatio = &ratio
+	}
+	return m
+}
+
+// Fairness reports per team how evenly the assignments made in the window
+// and the current open load are spread across active members. Teams whose
+// assignment Gini exceeds threshold are flagged as imbalanced.
+func (s *Service) Fairness(ctx context.Context, from, to *time.Time, team string, threshold float64) (models.FairnessStatsResponse, error) {
+	resp := models.FairnessStatsResponse{From: from, To: to, GiniThreshold: threshold, Teams: []models.TeamFairness{}}
+
+	loads, err := s.store.Stats().ReviewLoads(ctx, from, to, team)
+	if err != nil {
+		return resp, fmt.Errorf("failed to fetch fairness stats: %w", err)
+	}
+
+	var teams []string
+	assigned := map[string][]int{}
+	open := map[string][]int{}
+	for _, l := range loads {
+		if _, seen := assigned[l.TeamName]; !seen {
+			teams = append(teams, l.TeamName)
+		}
+		assigned[l.TeamName] = append(assigned[l.TeamName], l.Assigned)
+		open[l.TeamName] = append(open[l.TeamName], l.Open)
+	}
+
+	for _, name := range teams {
+		tf := models.TeamFairness{
+			TeamName:    name,
+			MemberCount: len(assigned[name]),
+			Assignments: computeFairness(assigned[name]),
+			OpenLoad:    computeFairness(open[name]),
+		}
+		tf.Imbalanced = tf.Assignments.Gini > threshold
+		resp.Teams = append(resp.Teams, tf)
+	}
+	return resp, nil
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeFairnessEven(t *testing.T) {
+	m := computeFairness([]int{3, 3, 3})
+	if m.Gini != 0 || m.StdDev != 0 {
+		t.Fatalf("expected perfectly even distribution, got %+v", m)
+	}
+	if m.MaxMinRatio == nil || *m.MaxMinRatio != 1 {
+		t.Fatalf("expected max/min ratio 1, got %v", m.MaxMinRatio)
+	}
+}
+
+func TestComputeFairnessSkewed(t *testing.T) {
+	m := computeFairness([]int{0, 0, 0, 4})
+	if math.Abs(m.Gini-0.75) > 1e-9 {
+		t.Fatalf("expected gini 0.75, got %v", m.Gini)
+	}
+	if m.Min != 0 || m.Max != 4 || m.Mean != 1 {
+		t.Fatalf("unexpected min/max/mean: %+v", m)
+	}
+	if m.MaxMinRatio != nil {
+		t.Fatalf("expected no max/min ratio when someone has no load, got %v", *m.MaxMinRatio)
+	}
+	if math.Abs(m.StdDev-math.Sqrt(3)) > 1e-9 {
+		t.Fatalf("expected std dev sqrt(3), got %v", m.StdDev)
+	}
+}
+
+func TestComputeFairnessEmpty(t *testing.T) {
+	m := computeFairness(nil)
+	if m.Gini != 0 || m.Max != 0 {
+		t.Fatalf("expected zero metrics, got %+v", m)
+	}
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"slices"
+)
+
+const defaultReviewerCount = 2
+
+var (
+	teamKinds       = []string{"department", "team", "squad"}
+	seniorityLevels = []string{"junior", "middle", "senior"}
+)
+
+type teamForest struct {
+	teams    map[string]repository.TeamInfo
+	children map[string][]string
+}
+
+func loadTeamForest(ctx context.Context, teams repository.TeamRepo) (*teamForest, error) {
+	list, err := teams.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &teamForest{teams: map[string]repository.TeamInfo{}, children: map[string][]string{}}
+	for _, t := range list {
+		f.teams[t.Name] = t
+		if t.Parent != "" {
+			f.children[t.Parent] = append(f.children[t.Parent], t.Name)
+		}
+	}
+	return f, nil
+}
+
+// chain returns the team followed by its ancestors up to the root.
+func (f *teamForest) chain(name string) []repository.TeamInfo {
+	var chain []repository.TeamInfo
+	for name != "" && len(chain) < repository.MaxHierarchyDepth {
+		t, ok := f.teams[name]
+		if !ok {
+			break
+		}
+		chain = append(chain, t)
+		name = t.Parent
+	}
+	return chain
+}
+
+func (f *teamForest) path(name string) []string {
+	chain := f.chain(name)
+	path := make([]string, 0, len(chain))
+	for i := len(chain) - 1; i >= 0; i-- {
+		path = append(path, chain[i].Name)
+	}
+	return path
+}
+
+// subtree returns the team and all teams below it. A team is listed once
+// even if parent_team links loop back to it.
+func (f *teamForest) subtree(name string) []string {
+	names := []string{name}
+	seen := map[string]bool{name: true}
+	for i := 0; i < len(names); i++ {
+		for _, child := range f.children[names[i]] {
+			if !seen[child] {
+				seen[child] = true
+				names = append(names, child)
+			}
+		}
+	}
+	return names
+}
+
+// resolvePolicy walks the chain from the team itself towards the root and
+// takes the nearest explicitly set value for every setting.
+func resolvePolicy(chain []repository.TeamInfo) models.EffectivePolicy {
+	p := models.EffectivePolicy{ReviewerCount: defaultReviewerCount, ReviewerCountFrom: "default"}
+	countSet, seniorSet, juniorSet := false, false, false
+	for _, t := range chain {
+		if t.Policy == nil {
+			continue
+		}
+		if !seniorSet && t.Policy.RequireSenior != nil {
+			p.RequireSenior = *t.Policy.RequireSenior
+			seniorSet = true
+		}
+		if !juniorSet && t.Policy.AddJuniorReviewer != nil {
+			p.AddJuniorReviewer = *t.Policy.AddJuniorReviewer
+			juniorSet = true
+		}
+		if !countSet && t.Policy.ReviewerCount != nil {
+			p.ReviewerCount = *t.Policy.ReviewerCount
+			p.ReviewerCountFrom = t.Name
+			countSet = true
+		}
+		if p.ReviewSLAHours == nil && t.Policy.ReviewSLAHours != nil {
+			p.ReviewSLAHours = t.Policy.ReviewSLAHours
+			p.ReviewSLAHoursFrom = t.Name
+		}
+	}
+	return p
+}
+
+func loadEffectivePolicy(ctx context.Context, teams repository.TeamRepo, teamName string) (models.EffectivePolicy, error) {
+	chain, err := teams.Chain(ctx, teamName)
+	if err != nil {
+		return models.EffectivePolicy{}, err
+	}
+	return resolvePolicy(chain), nil
+}
+
+// buildNode renders the team and its subteams. seen collects the teams already
+// rendered so that a parent_team loop is cut instead of followed forever.
+func (f *teamForest) buildNode(name string, members map[string][]models.TeamMember, seen map[string]bool) models.TeamNode {
+	seen[name] = true
+	t := f.teams[name]
+	node := models.TeamNode{
+		TeamName:        t.Name,
+		Kind:            t.Kind,
+		ParentTeam:      t.Parent,
+		Path:            f.path(name),
+		Policy:          t.Policy,
+		EffectivePolicy: resolvePolicy(f.chain(name)),
+		Members:         members[name],
+		Subteams:        []models.TeamNode{},
+	}
+	if node.Members == nil {
+		node.Members = []models.TeamMember{}
+	}
+	for _, child := range f.children[name] {
+		if !seen[child] {
+			node.Subteams = append(node.Subteams, f.buildNode(child, members, seen))
+		}
+	}
+	return node
+}
+
+func validTeamKind(kind string) bool {
+	return slices.Contains(teamKinds, kind)
+}
+
+func validSeniority(level string) bool {
+	return slices.Contains(seniorityLevels, level)
+}
+
+var errInvalidSeniority = invalid("seniority must be one of junior, middle, senior")
+
+// rollUpStats adds up the stats of the team and its subteams; seen cuts
+// parent_team loops like in buildNode.
+func (f *teamForest) rollUpStats(name string, own map[string]models.TeamAssignmentStats, seen map[string]bool) models.TeamAssignmentStats {
+	seen[name] = true
+	node := own[name]
+	node.TeamName = name
+	node.Kind = f.teams[name].Kind
+	node.TotalMemberCount = node.MemberCount
+	node.TotalAssignedPRCount = node.AssignedPRCount
+	node.Subteams = []models.TeamAssignmentStats{}
+	for _, child := range f.children[name] {
+		if seen[child] {
+			continue
+		}
+		sub := f.rollUpStats(child, own, seen)
+		node.TotalMemberCount += sub.TotalMemberCount
+		node.TotalAssignedPRCount += sub.TotalAssignedPRCount
+		node.Subteams = append(node.Subteams, sub)
+	}
+	return node
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"slices"
+)
+
+// AssignmentResult is a PR after reviewers were picked for it, with the
+// details of the selection.
+type AssignmentResult struct {
+	PR models.PullRequest
+	// ReplacedBy is the new reviewer of a reassignment.
+	ReplacedBy  string
+	CrossTeam   []string
+	Learning    []string
+	Warnings    []string
+	Explanation models.AssignmentExplanation
+}
+
+// CreatePR opens a PR with reviewers picked by the policy of the author's
+// team. Selection and insert share a transaction so that the reviewers are
+// checked against the same state they are written to.
+func (s *Service) CreatePR(ctx context.Context, req models.CreatePRRequest) (AssignmentResult, error) {
+	var res AssignmentResult
+	err := s.store.InTx(ctx, func(tx repository.Store) error {
+		if err := checkUserAccess(ctx, tx.Users(), req.AuthorID); err != nil {
+			return err
+		}
+		plan, err := planCreate(ctx, tx, req.AuthorID)
+		if err != nil {
+			return err
+		}
+		sel := plan.Selection
+
+		pr := models.PullRequest{
+			PullRequestID:     req.PullRequestID,
+			PullRequestName:   req.PullRequestName,
+			AuthorID:          req.AuthorID,
+			Status:            "OPEN",
+			AssignedReviewers: sel.Reviewers,
+			Version:           1,
+		}
+		err = tx.PullRequests().Create(ctx, pr)
+		if errors.Is(err, repository.ErrExists) {
+			return newError(CodePRExists, "PR id already exists")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to insert PR: %w", err)
+		}
+
+		res = AssignmentResult{
+			PR:          pr,
+			CrossTeam:   sel.CrossTeam,
+			Learning:    sel.Learning,
+			Warnings:    sel.Warnings,
+			Explanation: plan.explanation("create", req.PullRequestID),
+		}
+		return nil
+	})
+	if err != nil {
+		return AssignmentResult{}, err
+	}
+	s.invalidateStats()
+	return res, nil
+}
+
+// GetPR returns one PR.
+func (s *Service) GetPR(ctx context.Context, prID string) (models.PullRequest, error) {
+	pr, err := s.store.PullRequests().Get(ctx, prID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return pr, errPRNotFound
+	}
+	return pr, err
+}
+
+// MergePR marks the PR as merged if it matches cond. Merging an already
+// merged PR is a no-op. The PR row stays locked until the merge commits, so
+// a concurrent reassignment either finishes first or sees the PR as merged.
+func (s *Service) MergePR(ctx context.Context, prID string, cond Precondition) (_ models.PullRequest, err error) {
+	payload := auditPayload{IfMatch: cond}
+	defer s.auditFailure(ctx, ActionPRMerge, prID, payload, &err)
+
+	var pr models.PullRequest
+	merged := false
+	err = s.store.InTx(ctx, func(tx repository.Store) error {
+		prs := tx.PullRequests()
+
+		var err error
+		pr, err = prs.Lock(ctx, prID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return errPRNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load PR: %w", err)
+		}
+		if err := checkUserAccess(ctx, tx.Users(), pr.AuthorID); err != nil {
+			return err
+		}
+		if err := cond.check(pr.Version); err != nil {
+			return err
+		}
+		if pr.Status != "MERGED" {
+			if err := prs.Merge(ctx, prID); err != nil {
+				return fmt.Errorf("failed to merge PR: %w", err)
+			}
+			merged = true
+
+			if pr, err = prs.Get(ctx, prID); err != nil {
+				return fmt.Errorf("failed to fetch PR %s: %w", prID, err)
+			}
+		}
+		return recordAudit(ctx, tx, ActionPRMerge, prID, payload)
+	})
+	if err != nil {
+		return pr, err
+	}
+	if merged {
+		s.invalidateStats()
+	}
+	return pr, nil
+}
+
+// ReassignPR replaces oldReviewerID on an open PR with a candidate from the
+// old reviewer's team if the PR matches cond. The PR is locked while the
+// replacement is picked, so concurrent reassignments and merges of the PR
+// cannot overwrite each other.
+func (s *Service) ReassignPR(ctx context.Context, prID, oldReviewerID string, cond Precondition) (_ AssignmentResult, err error) {
+	payload := auditPayload{OldReviewerID: oldReviewerID, IfMatch: cond}
+	defer s.auditFailure(ctx, ActionPRReassign, prID, payload, &err)
+
+	var res AssignmentResult
+	err = s.store.InTx(ctx, func(tx repository.Store) error {
+		plan, err := planReassign(ctx, tx, prID, oldReviewerID, cond, true)
+		if err != nil {
+			return err
+		}
+		if err := checkUserAccess(ctx, tx.Users(), plan.AuthorID); err != nil {
+			return err
+		}
+		sel := plan.Selection
+		if len(sel.Reviewers) == 0 {
+			return newError(CodeNoCandidate, "no active replacement candidate in team")
+		}
+
+		newReviewer := sel.Reviewers[0]
+		assigned := plan.Assigned
+		assigned[plan.Index] = newReviewer
+
+		version, err := tx.PullRequests().SetReviewers(ctx, prID, assigned)
+		if err != nil {
+			return fmt.Errorf("failed to save reassignment: %w", err)
+		}
+
+		res = AssignmentResult{
+			PR:          models.PullRequest{PullRequestID: prID, AssignedReviewers: assigned, Version: version},
+			ReplacedBy:  newReviewer,
+			CrossTeam:   sel.CrossTeam,
+			Learning:    sel.Learning,
+			Warnings:    sel.Warnings,
+			Explanation: plan.explanation("reassign", prID),
+		}
+		return recordAudit(ctx, tx, ActionPRReassign, prID, payload)
+	})
+	if err != nil {
+		return AssignmentResult{}, err
+	}
+	s.invalidateStats()
+	return res, nil
+}
+
+var reviewVerdicts = []string{"APPROVED", "CHANGES_REQUESTED"}
+
+// SubmitReview records the verdict of an assigned reviewer on an open PR.
+// The PR is locked so that the verdict cannot land after a concurrent merge.
+func (s *Service) SubmitReview(ctx context.Context, req models.SubmitReviewRequest) (models.Review, error) {
+	if !slices.Contains(reviewVerdicts, req.Verdict) {
+		return models.Review{}, invalid("verdict must be one of APPROVED, CHANGES_REQUESTED")
+	}
+
+	var review models.Review
+	err := s.store.InTx(ctx, func(tx repository.Store) error {
+		pr, err := tx.PullRequests().Lock(ctx, req.PullRequestID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return errPRNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load PR: %w", err)
+		}
+		if err := checkUserAccess(ctx, tx.Users(), pr.AuthorID); err != nil {
+			return err
+		}
+		if pr.Status == "MERGED" {
+			return newError(CodePRMerged, "cannot review merged PR")
+		}
+
+		review, err = tx.PullRequests().SubmitReview(ctx, req.PullRequestID, req.ReviewerID, req.Verdict)
+		if errors.Is(err, repository.ErrNotFound) {
+			return errNotAssigned
+		}
+		if err != nil {
+			return fmt.Errorf("failed to record verdict: %w", err)
+		}
+		return nil
+	})
+	return review, err
+}
+
+// ListPRs calls fn for every PR matching filter, oldest first.
+func (s *Service) ListPRs(ctx context.Context, filter repository.PullRequestFilter, fn func(models.PullRequest) error) error {
+	return s.store.PullRequests().List(ctx, filter, fn)
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"slices"
+)
+
+// Candidate decisions and the rules behind them, as reported by the explain
+// endpoint.
+const (
+	decisionSelected    = "selected"
+	decisionExcluded    = "excluded"
+	decisionNotSelected = "not_selected"
+
+	reasonAuthor          = "author"
+	reasonAlreadyAssigned = "already_assigned"
+	reasonReplaced        = "replaced_reviewer"
+	reasonInactive        = "inactive"
+	reasonRequiredSenior  = "required_senior"
+	reasonCandidateOrder  = "candidate_order"
+	reasonLearningJunior  = "learning_junior"
+	reasonCountReached    = "reviewer_count_reached"
+)
+
+// selectionRules describes the reviewer composition a team asks for.
+// AddJunior requests one extra junior on top of Count as a learning reviewer.
+type selectionRules struct {
+	Count         int
+	RequireSenior bool
+	AddJunior     bool
+}
+
+type reviewerSelection struct {
+	Reviewers []string
+	CrossTeam []string
+	Learning  []string
+	Warnings  []string
+	Trace     []models.CandidateExplanation
+}
+
+func rulesFromPolicy(p models.EffectivePolicy) selectionRules {
+	return selectionRules{Count: p.ReviewerCount, RequireSenior: p.RequireSenior, AddJunior: p.AddJuniorReviewer}
+}
+
+type eligibleCandidate struct {
+	repository.Candidate
+	crossTeam bool
+	trace     int
+}
+
+// pickReviewers takes up to rules.Count reviewers, exhausting each tier before
+// drawing from the next one. A required senior is taken first, wherever it is
+// found; constraints that cannot be met are reported in Warnings. exclude maps
+// user IDs that must not be picked to the reason recorded in the trace.
+func pickReviewers(tiers []repository.CandidateTier, exclude map[string]string, rules selectionRules) reviewerSelection {
+	sel := reviewerSelection{
+		Reviewers: []string{},
+		CrossTeam: []string{},
+		Learning:  []string{},
+		Warnings:  []string{},
+		Trace:     []models.CandidateExplanation{},
+	}
+
+	var pool []eligibleCandidate
+	for _, tier := range tiers {
+		for _, c := range tier.Candidates {
+			ex := models.CandidateExplanation{
+				UserID:    c.UserID,
+				TeamName:  tier.TeamName,
+				Source:    tier.Source,
+				Seniority: c.Seniority,
+				Rank:      len(sel.Trace) + 1,
+				Decision:  decisionNotSelected,
+				Reason:    reasonCountReached,
+			}
+			switch {
+			case exclude[c.UserID] != "":
+				ex.Decision, ex.Reason = decisionExcluded, exclude[c.UserID]
+			case !c.IsActive:
+				ex.Decision, ex.Reason = decisionExcluded, reasonInactive
+			default:
+				pool = append(pool, eligibleCandidate{c, tier.CrossTeam, len(sel.Trace)})
+			}
+			sel.Trace = append(sel.Trace, ex)
+		}
+	}
+
+	taken := make([]bool, len(pool))
+	take := func(i int, reason string) {
+		taken[i] = true
+		sel.Trace[pool[i].trace].Decision = decisionSelected
+		sel.Trace[pool[i].trace].Reason = reason
+		sel.Reviewers = append(sel.Reviewers, pool[i].UserID)
+		if pool[i].crossTeam {
+			sel.CrossTeam = append(sel.CrossTeam, pool[i].UserID)
+		}
+	}
+	firstFree := func(seniority string) int {
+		for i, c := range pool {
+			if !taken[i] && (seniority == "" || c.Seniority == seniority) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	if rules.RequireSenior && rules.Count > 0 {
+		if i := firstFree("senior"); i >= 0 {
+			take(i, reasonRequiredSenior)
+		} else {
+			sel.Warnings = append(sel.Warnings, "no active senior reviewer available")
+		}
+	}
+	for len(sel.Reviewers) < rules.Count {
+		i := firstFree("")
+		if i < 0 {
+			sel.Warnings = append(sel.Warnings,
+				fmt.Sprintf("only %d of %d reviewers available", len(sel.Reviewers), rules.Count))
+			break
+		}
+		take(i, reasonCandidateOrder)
+	}
+	if rules.AddJunior {
+		if i := firstFree("junior"); i >= 0 {
+			take(i, reasonLearningJunior)
+			sel.Learning = append(sel.Learning, pool[i].UserID)
+		} else {
+			sel.Warnings = append(sel.Warnings, "no active junior reviewer available for learning")
+		}
+	}
+
+	return sel
+}
+
+// Selection strategies decide the order of candidates inside each tier; the
+// tiers themselves always keep their priority. Production selection uses
+// strategyFirst, the others can be evaluated with the simulation endpoint.
+const (
+	strategyFirst       = "first"
+	strategyLeastLoaded = "least_loaded"
+	strategyRandom      = "random"
+)
+
+var selectionStrategies = []string{strategyFirst, strategyLeastLoaded, strategyRandom}
+
+func orderTiers(tiers []repository.CandidateTier, strategy string, load map[string]int, rng *rand.Rand) []repository.CandidateTier {
+	ordered := make([]repository.CandidateTier, len(tiers))
+	for i, tier := range tiers {
+		tier.Candidates = slices.Clone(tier.Candidates)
+		switch strategy {
+		case strategyLeastLoaded:
+			slices.SortStableFunc(tier.Candidates, func(a, b repository.Candidate) int {
+				return load[a.UserID] - load[b.UserID]
+			})
+		case strategyRandom:
+			rng.Shuffle(len(tier.Candidates), func(i, j int) {
+				tier.Candidates[i], tier.Candidates[j] = tier.Candidates[j], tier.Candidates[i]
+			})
+		}
+		ordered[i] = tier
+	}
+	return ordered
+}
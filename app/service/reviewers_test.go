@@ -0,0 +1,76 @@
+package service
+
+import (
+	"reviewer-service/app/repository"
+	"slices"
+	"testing"
+)
+
+func testTiers() []repository.CandidateTier {
+	return []repository.CandidateTier{
+		{TeamName: "home", Source: "home", Candidates: []repository.Candidate{
+			{UserID: "a", Seniority: "middle", IsActive: true},
+			{UserID: "b", Seniority: "junior", IsActive: true},
+			{UserID: "c", Seniority: "middle", IsActive: false},
+		}},
+		{TeamName: "partner", Source: "fallback", CrossTeam: true, Candidates: []repository.Candidate{
+			{UserID: "d", Seniority: "senior", IsActive: true},
+		}},
+	}
+}
+
+func TestPickReviewersComposition(t *testing.T) {
+	sel := pickReviewers(testTiers(), map[string]string{"a": reasonAuthor},
+		selectionRules{Count: 1, RequireSenior: true, AddJunior: true})
+
+	if !slices.Equal(sel.Reviewers, []string{"d", "b"}) {
+		t.Fatalf("expected senior d and learning junior b, got %v", sel.Reviewers)
+	}
+	if !slices.Equal(sel.CrossTeam, []string{"d"}) || !slices.Equal(sel.Learning, []string{"b"}) {
+		t.Fatalf("unexpected cross-team %v or learning %v", sel.CrossTeam, sel.Learning)
+	}
+	if len(sel.Warnings) != 0 {
+		t.Fatalf("expected no warnings, got %v", sel.Warnings)
+	}
+
+	reasons := map[string]string{}
+	for _, c := range sel.Trace {
+		reasons[c.UserID] = c.Reason
+	}
+	if reasons["a"] != reasonAuthor || reasons["c"] != reasonInactive || reasons["d"] != reasonRequiredSenior {
+		t.Fatalf("unexpected trace reasons: %v", reasons)
+	}
+}
+
+func TestPickReviewersNotEnoughCandidates(t *testing.T) {
+	sel := pickReviewers(testTiers(), map[string]string{"a": reasonAuthor, "d": reasonAlreadyAssigned},
+		selectionRules{Count: 2, RequireSenior: true})
+
+	if !slices.Equal(sel.Reviewers, []string{"b"}) {
+		t.Fatalf("expected only b, got %v", sel.Reviewers)
+	}
+	if len(sel.Warnings) != 2 {
+		t.Fatalf("expected missing senior and short count warnings, got %v", sel.Warnings)
+	}
+}
+
+func TestOrderTiersLeastLoaded(t *testing.T) {
+	tiers := []repository.CandidateTier{{TeamName: "home", Candidates: []repository.Candidate{
+		{UserID: "a", IsActive: true},
+		{UserID: "b", IsActive: true},
+		{UserID: "c", IsActive: true},
+	}}}
+
+	ordered := orderTiers(tiers, strategyLeastLoaded, map[string]int{"a": 2, "b": 1}, nil)
+
+	var ids []string
+	for _, c := range ordered[0].Candidates {
+		ids = append(ids, c.UserID)
+	}
+	if !slices.Equal(ids, []string{"c", "b", "a"}) {
+		t.Fatalf("expected least loaded first, got %v", ids)
+	}
+	if tiers[0].Candidates[0].UserID != "a" {
+		t.Fatalf("expected original tiers to stay untouched")
+	}
+}
@@ -0,0 +1,108 @@
+// Package service implements the reviewer assignment rules on top of a
+// repository.Store. Operations take and return plain model values and report
+// rule violations as *Error, so every transport (HTTP today, a CLI or gRPC
+// later) applies the same logic and only maps the error codes.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reviewer-service/app/repository"
+	"slices"
+	"time"
+)
+
+// Code classifies a domain error. The values match the error codes of the
+// HTTP API.
+type Code string
+
+const (
+	// CodeValidation rejects a request whose fields break a rule by
+	// themselves, before any data is looked at.
+	CodeValidation Code = "VALIDATION_ERROR"
+	// CodeBadRequest rejects a request that cannot be carried out on the
+	// current data.
+	CodeBadRequest  Code = "BAD_REQUEST"
+	CodeNotFound    Code = "NOT_FOUND"
+	CodeTeamExists  Code = "TEAM_EXISTS"
+	CodePRExists    Code = "PR_EXISTS"
+	CodePRMerged    Code = "PR_MERGED"
+	CodeNotAssigned Code = "NOT_ASSIGNED"
+	CodeNoCandidate Code = "NO_CANDIDATE"
+	// CodePreconditionFailed rejects a change to a resource whose version is
+	// not the one the client expected.
+	CodePreconditionFailed Code = "PRECONDITION_FAILED"
+	// CodeUnauthorized rejects a missing, unknown or revoked API token.
+	CodeUnauthorized Code = "UNAUTHORIZED"
+	// CodeForbidden rejects an operation the caller's role does not allow.
+	CodeForbidden Code = "FORBIDDEN"
+)
+
+// Error is a violated domain rule. Message is meant for the client; Err, when
+// set, is the underlying cause and is only worth logging.
+type Error struct {
+	Code    Code
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+func newError(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func invalid(message string) *Error {
+	return newError(CodeValidation, message)
+}
+
+// Precondition lists the versions a client expects a resource to have, as
+// sent in If-Match. A nil Precondition always holds; otherwise the current
+// version must be one of the listed ones.
+type Precondition []int64
+
+func (p Precondition) check(version int64) error {
+	if p == nil || slices.Contains(p, version) {
+		return nil
+	}
+	return newError(CodePreconditionFailed, "resource was modified since it was read")
+}
+
+// CodeOf returns the code of the domain error in err's chain, or "" when err
+// is not a domain error.
+func CodeOf(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+type Service struct {
+	store      repository.Store
+	statsCache *statsCache
+}
+
+// New returns a Service over store. Assignment stats are cached for cacheTTL;
+// zero disables the cache.
+func New(store repository.Store, cacheTTL time.Duration) *Service {
+	return &Service{store: store, statsCache: newStatsCache(cacheTTL)}
+}
+
+// Ping checks that the store is reachable.
+func (s *Service) Ping(ctx context.Context) error {
+	return s.store.Ping(ctx)
+}
+
+// CheckSchema verifies that the store has the expected schema.
+func (s *Service) CheckSchema(ctx context.Context) error {
+	return s.store.CheckSchema(ctx)
+}
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reviewer-service/app/auth"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"reviewer-service/app/repository/memory"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	s := New(memory.New(), 0)
+	_, err := s.CreateTeam(context.Background(), models.Team{TeamName: "backend", Members: []models.TeamMember{
+		{UserID: "u1", Username: "Alice", IsActive: true},
+		{UserID: "u2", Username: "Bob", IsActive: true},
+		{UserID: "u3", Username: "Carol", IsActive: true},
+	}})
+	if err != nil {
+		t.Fatalf("create team: %v", err)
+	}
+	return s
+}
+
+func TestCreateTeamExists(t *testing.T) {
+	s := newTestService(t)
+	_, err := s.CreateTeam(context.Background(), models.Team{TeamName: "backend"})
+	if CodeOf(err) != CodeTeamExists {
+		t.Fatalf("expected TEAM_EXISTS, got %v", err)
+	}
+}
+
+func TestUpdateTeamRejectsParentBeyondDepthLimit(t *testing.T) {
+	ctx := context.Background()
+	s := New(memory.New(), 0)
+	for i := range 40 {
+		team := models.Team{TeamName: fmt.Sprintf("t%d", i)}
+		if i > 0 {
+			team.ParentTeam = fmt.Sprintf("t%d", i-1)
+		}
+		if _, err := s.CreateTeam(ctx, team); err != nil {
+			t.Fatalf("create team: %v", err)
+		}
+	}
+
+	parent := "t39"
+	if _, err := s.UpdateTeam(ctx, models.UpdateTeamRequest{TeamName: "t0", ParentTeam: &parent}, nil); CodeOf(err) != CodeValidation {
+		t.Fatalf("expected VALIDATION_ERROR, got %v", err)
+	}
+}
+
+func TestTeamTreeStopsAtCycle(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	s := New(store, 0)
+	if _, err := s.CreateTeam(ctx, models.Team{TeamName: "a"}); err != nil {
+		t.Fatalf("create team: %v", err)
+	}
+	if _, err := s.CreateTeam(ctx, models.Team{TeamName: "b", ParentTeam: "a"}); err != nil {
+		t.Fatalf("create team: %v", err)
+	}
+	// Written through the store: the service refuses to create the cycle.
+	kind, parent := "team", "b"
+	if err := store.Teams().Update(ctx, models.UpdateTeamRequest{TeamName: "a", Kind: &kind, ParentTeam: &parent}); err != nil {
+		t.Fatalf("create cycle: %v", err)
+	}
+
+	tree, err := s.TeamTree(ctx, "a")
+	if err != nil {
+		t.Fatalf("team tree: %v", err)
+	}
+	if len(tree.Subteams) != 1 || len(tree.Subteams[0].Subteams) != 0 {
+		t.Fatalf("expected the loop back to a to be cut, got %+v", tree)
+	}
+	if _, err := s.TeamAssignmentStatsTree(ctx, "a"); err != nil {
+		t.Fatalf("stats tree: %v", err)
+	}
+}
+
+func TestReassignMergedPR(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	res, err := s.CreatePR(ctx, models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Fix", AuthorID: "u1"})
+	if err != nil {
+		t.Fatalf("create PR: %v", err)
+	}
+	if _, err := s.MergePR(ctx, "pr-1", nil); err != nil {
+		t.Fatalf("merge PR: %v", err)
+	}
+
+	_, err = s.ReassignPR(ctx, "pr-1", res.PR.AssignedReviewers[0], nil)
+	if CodeOf(err) != CodePRMerged {
+		t.Fatalf("expected PR_MERGED, got %v", err)
+	}
+}
+
+func TestSubmitReviewRules(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	if _, err := s.CreatePR(ctx, models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Fix", AuthorID: "u1"}); err != nil {
+		t.Fatalf("create PR: %v", err)
+	}
+
+	cases := []struct {
+		req  models.SubmitReviewRequest
+		want Code
+	}{
+		{models.SubmitReviewRequest{PullRequestID: "pr-1", ReviewerID: "u2", Verdict: "LGTM"}, CodeValidation},
+		{models.SubmitReviewRequest{PullRequestID: "pr-2", ReviewerID: "u2", Verdict: "APPROVED"}, CodeNotFound},
+		{models.SubmitReviewRequest{PullRequestID: "pr-1", ReviewerID: "u1", Verdict: "APPROVED"}, CodeNotAssigned},
+	}
+	for _, c := range cases {
+		if _, err := s.SubmitReview(ctx, c.req); CodeOf(err) != c.want {
+			t.Fatalf("%+v: expected %s, got %v", c.req, c.want, err)
+		}
+	}
+}
+
+func TestDeactivateUsersRollsBackWithoutCandidate(t *testing.T) {
+	ctx := context.Background()
+	s := New(memory.New(), 0)
+	if _, err := s.CreateTeam(ctx, models.Team{TeamName: "solo", Members: []models.TeamMember{
+		{UserID: "u1", Username: "Alice", IsActive: true},
+	}}); err != nil {
+		t.Fatalf("create team: %v", err)
+	}
+	if _, err := s.CreatePR(ctx, models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Fix", AuthorID: "u1"}); err != nil {
+		t.Fatalf("create PR: %v", err)
+	}
+
+	if _, err := s.DeactivateUsers(ctx, []string{"u1"}); CodeOf(err) != CodeBadRequest {
+		t.Fatalf("expected BAD_REQUEST, got %v", err)
+	}
+	team, err := s.GetTeam(ctx, "solo")
+	if err != nil {
+		t.Fatalf("get team: %v", err)
+	}
+	if !team.Members[0].IsActive {
+		t.Fatal("expected deactivation to be rolled back")
+	}
+}
+
+// lockFailingStore fails every row lock on PRs.
+type lockFailingStore struct{ repository.Store }
+
+func (s lockFailingStore) PullRequests() repository.PullRequestRepo {
+	return lockFailingPullRequests{s.Store.PullRequests()}
+}
+
+type lockFailingPullRequests struct{ repository.PullRequestRepo }
+
+func (lockFailingPullRequests) Lock(ctx context.Context, prID string) (models.PullRequest, error) {
+	return models.PullRequest{}, errors.New("lock not allowed")
+}
+
+func TestExplainReassignDoesNotLock(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	s := New(store, 0)
+	if _, err := s.CreateTeam(ctx, models.Team{TeamName: "backend", Members: []models.TeamMember{
+		{UserID: "u1", Username: "Alice", IsActive: true},
+		{UserID: "u2", Username: "Bob", IsActive: true},
+		{UserID: "u3", Username: "Carol", IsActive: true},
+		{UserID: "u4", Username: "Dave", IsActive: true},
+	}}); err != nil {
+		t.Fatalf("create team: %v", err)
+	}
+	res, err := s.CreatePR(ctx, models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Fix", AuthorID: "u1"})
+	if err != nil {
+		t.Fatalf("create PR: %v", err)
+	}
+
+	explain := New(lockFailingStore{store}, 0)
+	req := models.ExplainAssignmentRequest{PullRequestID: "pr-1", OldReviewerID: res.PR.AssignedReviewers[0]}
+	if _, err := explain.ExplainAssignment(ctx, req); err != nil {
+		t.Fatalf("expected explain to read the PR without locking it: %v", err)
+	}
+}
+
+// slowStore widens the gap between reading a PR and writing it back, so that
+// unsynchronized read-modify-write cycles interleave.
+type slowStore struct{ repository.Store }
+
+func (s slowStore) PullRequests() repository.PullRequestRepo {
+	return slowPullRequests{s.Store.PullRequests()}
+}
+
+func (s slowStore) InTx(ctx context.Context, fn func(tx repository.Store) error) error {
+	return s.Store.InTx(ctx, func(tx repository.Store) error { return fn(slowStore{tx}) })
+}
+
+type slowPullRequests struct{ repository.PullRequestRepo }
+
+func (r slowPullRequests) SetReviewers(ctx context.Context, prID string, reviewers []string) (int64, error) {
+	time.Sleep(time.Millisecond)
+	return r.PullRequestRepo.SetReviewers(ctx, prID, reviewers)
+}
+
+func (r slowPullRequests) Merge(ctx context.Context, prID string) error {
+	time.Sleep(time.Millisecond)
+	return r.PullRequestRepo.Merge(ctx, prID)
+}
+
+func TestConcurrentReassignAndMerge(t *testing.T) {
+	ctx := context.Background()
+	s := New(slowStore{memory.New()}, 0)
+	members := []models.TeamMember{}
+	for i := 1; i <= 6; i++ {
+		members = append(members, models.TeamMember{UserID: fmt.Sprintf("u%d", i), Username: fmt.Sprintf("User %d", i), IsActive: true})
+	}
+	if _, err := s.CreateTeam(ctx, models.Team{TeamName: "backend", Members: members}); err != nil {
+		t.Fatalf("create team: %v", err)
+	}
+	created, err := s.CreatePR(ctx, models.CreatePRRequest{PullRequestID: "pr-1", PullRequestName: "Fix", AuthorID: "u1"})
+	if err != nil {
+		t.Fatalf("create PR: %v", err)
+	}
+
+	balance := map[string]int{}
+	for _, u := range created.PR.AssignedReviewers {
+		balance[u]++
+	}
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		merged models.PullRequest
+	)
+	for i := range 20 {
+		old := fmt.Sprintf("u%d", 2+i%5)
+		wg.Go(func() {
+			res, err := s.ReassignPR(ctx, "pr-1", old, nil)
+			if err != nil {
+				if code := CodeOf(err); code != CodeNotAssigned && code != CodePRMerged && code != CodeNoCandidate {
+					t.Errorf("reassign %s: %v", old, err)
+				}
+				return
+			}
+			mu.Lock()
+			balance[old]--
+			balance[res.ReplacedBy]++
+			mu.Unlock()
+		})
+		if i == 10 {
+			wg.Go(func() {
+				pr, err := s.MergePR(ctx, "pr-1", nil)
+				if err != nil {
+					t.Errorf("merge: %v", err)
+				}
+				mu.Lock()
+				merged = pr
+				mu.Unlock()
+			})
+		}
+	}
+	wg.Wait()
+
+	final, err := s.store.PullRequests().Get(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("get PR: %v", err)
+	}
+	if !slices.Equal(final.AssignedReviewers, merged.AssignedReviewers) {
+		t.Fatalf("reviewers changed after merge: merged with %v, now %v", merged.AssignedReviewers, final.AssignedReviewers)
+	}
+	for u, n := range balance {
+		want := 0
+		if slices.Contains(final.AssignedReviewers, u) {
+			want = 1
+		}
+		if n != want {
+			t.Fatalf("lost update: %s has balance %d, final reviewers %v", u, n, final.AssignedReviewers)
+		}
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	s := newTestService(t)
+	lead := auth.WithPrincipal(context.Background(), auth.Principal{TokenID: "tok_1", Name: "lead", Role: auth.RoleTeamLead, Team: "backend"})
+	outsider := auth.WithPrincipal(context.Background(), auth.Principal{TokenID: "tok_2", Name: "outsider", Role: auth.RoleTeamLead, Team: "frontend"})
+
+	if _, err := s.SetUserActive(lead, "u2", false); err != nil {
+		t.Fatalf("set active: %v", err)
+	}
+	if _, err := s.SetUserActive(outsider, "u1", false); CodeOf(err) != CodeForbidden {
+		t.Fatalf("expected FORBIDDEN, got %v", err)
+	}
+	if _, err := s.DeactivateUsers(lead, []string{"u1", "u3"}); err != nil {
+		t.Fatalf("deactivate: %v", err)
+	}
+
+	entries, err := s.ListAudit(context.Background(), repository.AuditFilter{Limit: 10})
+	if err != nil {
+		t.Fatalf("list audit: %v", err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, fmt.Sprintf("%s %s %s %s", e.Actor, e.Action, e.Target, e.Result))
+	}
+	want := []string{
+		"lead users.deactivate u1,u3 OK",
+		"outsider user.set_active u1 FORBIDDEN",
+		"lead user.set_active u2 OK",
+		"anonymous team.create backend OK",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected entries %q, got %q", want, got)
+	}
+	if entries[2].Payload != `{"is_active":false}` || entries[2].TokenID != "tok_1" {
+		t.Fatalf("unexpected entry %+v", entries[2])
+	}
+
+	create, err := s.ListAudit(context.Background(), repository.AuditFilter{Action: ActionTeamCreate, Limit: 1})
+	if err != nil || len(create) != 1 || !strings.Contains(create[0].Payload, `"kind":"team"`) || !strings.Contains(create[0].Payload, `"seniority":"middle"`) {
+		t.Fatalf("expected the created team with defaults applied, got %+v (%v)", create, err)
+	}
+
+	entries, err = s.ListAudit(context.Background(), repository.AuditFilter{Target: "u3", Limit: 10})
+	if err != nil || len(entries) != 1 || entries[0].Action != ActionUsersDeactivate {
+		t.Fatalf("expected the bulk deactivation to match its second target, got %+v (%v)", entries, err)
+	}
+	entries, err = s.ListAudit(context.Background(), repository.AuditFilter{Actor: "tok_1", Limit: 1})
+	if err != nil || len(entries) != 1 || entries[0].Action != ActionUsersDeactivate {
+		t.Fatalf("expected the newest entry of tok_1, got %+v (%v)", entries, err)
+	}
+}
+
+func TestAuditTokens(t *testing.T) {
+	s := newTestService(t)
+	admin := auth.WithPrincipal(context.Background(), auth.Principal{TokenID: "tok_admin", Name: "admin", Role: auth.RoleAdmin})
+
+	token, secret, err := s.IssueToken(admin, models.IssueTokenRequest{Name: "ci", Role: "team-lead", TeamName: "backend"})
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+	if _, err := s.RevokeToken(admin, token.TokenID); err != nil {
+		t.Fatalf("revoke token: %v", err)
+	}
+	if _, err := s.RevokeToken(admin, "tok_missing"); CodeOf(err) != CodeNotFound {
+		t.Fatalf("expected NOT_FOUND, got %v", err)
+	}
+
+	entries, err := s.ListAudit(context.Background(), repository.AuditFilter{Limit: 3})
+	if err != nil {
+		t.Fatalf("list audit: %v", err)
+	}
+	var got []string
+	for _, e := range entries {
+		if strings.Contains(e.Payload, secret) {
+			t.Fatalf("secret leaked into audit entry %+v", e)
+		}
+		got = append(got, fmt.Sprintf("%s %s %s %s %s", e.Actor, e.Action, e.Target, e.Payload, e.Result))
+	}
+	want := []string{
+		"admin token.revoke tok_missing  NOT_FOUND",
+		fmt.Sprintf(`admin token.revoke %s {"role":"team-lead"} OK`, token.TokenID),
+		fmt.Sprintf(`admin token.issue %s {"role":"team-lead","team_name":"backend"} OK`, token.TokenID),
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected entries %q, got %q", want, got)
+	}
+}
+
+// failingAuditStore fails every audit write.
+type failingAuditStore struct{ repository.Store }
+
+func (s failingAuditStore) Audit() repository.AuditRepo {
+	return failingAudit{s.Store.Audit()}
+}
+
+func (s failingAuditStore) InTx(ctx context.Context, fn func(tx repository.Store) error) error {
+	return s.Store.InTx(ctx, func(tx repository.Store) error { return fn(failingAuditStore{tx}) })
+}
+
+type failingAudit struct{ repository.AuditRepo }
+
+func (failingAudit) Append(ctx context.Context, entry models.AuditEntry) error {
+	return errors.New("audit log unavailable")
+}
+
+func TestAuditFailureRollsBackAction(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	if _, err := New(store, 0).CreateTeam(ctx, models.Team{TeamName: "backend", Members: []models.TeamMember{
+		{UserID: "u1", Username: "Alice", IsActive: true},
+	}}); err != nil {
+		t.Fatalf("create team: %v", err)
+	}
+
+	s := New(failingAuditStore{store}, 0)
+	if _, err := s.DeactivateUsers(ctx, []string{"u1"}); err == nil {
+		t.Fatal("expected the deactivation to fail without an audit entry")
+	}
+	user, err := s.store.Users().Get(ctx, "u1")
+	if err != nil || !user.IsActive {
+		t.Fatalf("expected the deactivation to be rolled back, got %+v (%v)", user, err)
+	}
+}
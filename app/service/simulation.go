@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"math/rand/v2"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"slices"
+	"sort"
+	"time"
+)
+
+const defaultSimulationWindow = 30 * 24 * time.Hour
+
+// simulatedPR keeps the simulated reviewers of a PR until it is merged.
+type simulatedPR struct {
+	Reviewers []string
+	MergedAt  *time.Time
+}
+
+// simulation replays PR creations against the current team structure. Open
+// load is tracked in simulated time so that least_loaded sees reviews being
+// released when the historical PR was merged.
+type simulation struct {
+	ctx      context.Context
+	teams    repository.TeamRepo
+	strategy string
+	count    *int
+	rng      *rand.Rand
+
+	tiers    map[string][]repository.CandidateTier
+	policies map[string]models.EffectivePolicy
+
+	eligible  map[string]bool
+	actual    map[string]int
+	simulated map[string]int
+	openLoad  map[string]int
+	open      []simulatedPR
+
+	actualEmpty    int
+	simulatedEmpty int
+}
+
+func (s *simulation) teamData(teamName string) ([]repository.CandidateTier, models.EffectivePolicy, error) {
+	if tiers, ok := s.tiers[teamName]; ok {
+		return tiers, s.policies[teamName], nil
+	}
+	policy, err := loadEffectivePolicy(s.ctx, s.teams, teamName)
+	if err != nil {
+		return nil, policy, err
+	}
+	tiers, err := s.teams.CandidateTiers(s.ctx, teamName)
+	if err != nil {
+		return nil, policy, err
+	}
+	s.tiers[teamName], s.policies[teamName] = tiers, policy
+	return tiers, policy, nil
+}
+
+func (s *simulation) releaseMerged(now time.Time) {
+	open := s.open[:0]
+	for _, pr := range s.open {
+		if pr.MergedAt != nil && !pr.MergedAt.After(now) {
+			for _, id := range pr.Reviewers {
+				s.openLoad[id]--
+			}
+			continue
+		}
+		open = append(open, pr)
+	}
+	s.open = open
+}
+
+func (s *simulation) replay(pr repository.HistoricalPR) error {
+	s.releaseMerged(pr.CreatedAt)
+
+	for _, id := range pr.Reviewers {
+		s.actual[id]++
+		s.eligible[id] = true
+	}
+	if len(pr.Reviewers) == 0 {
+		s.actualEmpty++
+	}
+
+	tiers, policy, err := s.teamData(pr.TeamName)
+	if err != nil {
+		return err
+	}
+	rules := rulesFromPolicy(policy)
+	if s.count != nil {
+		rules.Count = *s.count
+	}
+
+	ordered := orderTiers(tiers, s.strategy, s.openLoad, s.rng)
+	sel := pickReviewers(ordered, map[string]string{pr.AuthorID: reasonAuthor}, rules)
+	for _, c := range sel.Trace {
+		if c.Decision != decisionExcluded {
+			s.eligible[c.UserID] = true
+		}
+	}
+	for _, id := range sel.Reviewers {
+		s.simulated[id]++
+		s.openLoad[id]++
+	}
+	if len(sel.Reviewers) == 0 {
+		s.simulatedEmpty++
+	}
+	s.open = append(s.open, simulatedPR{Reviewers: sel.Reviewers, MergedAt: pr.MergedAt})
+	return nil
+}
+
+func (s *simulation) result(from, to time.Time, prCount int) models.SimulationResult {
+	res := models.SimulationResult{
+		From:             from,
+		To:               to,
+		Strategy:         s.strategy,
+		ReviewerCount:    s.count,
+		PullRequestCount: prCount,
+		Users:            []models.UserLoadComparison{},
+	}
+
+	var actualLoads, simulatedLoads []int
+	for id := range s.eligible {
+		res.Users = append(res.Users, models.UserLoadComparison{
+			UserID:         id,
+			ActualCount:    s.actual[id],
+			SimulatedCount: s.simulated[id],
+		})
+		actualLoads = append(actualLoads, s.actual[id])
+		simulatedLoads = append(simulatedLoads, s.simulated[id])
+		res.Actual.TotalAssignments += s.actual[id]
+		res.Simulated.TotalAssignments += s.simulated[id]
+	}
+	sort.Slice(res.Users, func(i, j int) bool { return res.Users[i].UserID < res.Users[j].UserID })
+
+	res.Actual.PRsWithoutReviewers = s.actualEmpty
+	res.Simulated.PRsWithoutReviewers = s.simulatedEmpty
+	res.Actual.Fairness = computeFairness(actualLoads)
+	res.Simulated.Fairness = computeFairness(simulatedLoads)
+	return res
+}
+
+// Simulate replays the PRs created in the requested window (the last 30 days
+// by default) against the current team structure with the given strategy and
+// compares the resulting load with the actual assignments.
+func (s *Service) Simulate(ctx context.Context, req models.SimulationRequest) (models.SimulationResult, error) {
+	if req.Strategy == "" {
+		req.Strategy = strategyFirst
+	}
+	if !slices.Contains(selectionStrategies, req.Strategy) {
+		return models.SimulationResult{}, invalid("strategy must be one of first, least_loaded, random")
+	}
+	if req.ReviewerCount != nil && *req.ReviewerCount < 0 {
+		return models.SimulationResult{}, invalid("reviewer_count must not be negative")
+	}
+
+	to := time.Now()
+	if req.To != nil {
+		to = *req.To
+	}
+	from := to.Add(-defaultSimulationWindow)
+	if req.From != nil {
+		from = *req.From
+	}
+	if !from.Before(to) {
+		return models.SimulationResult{}, invalid("from must be before to")
+	}
+
+	prs, err := s.store.Stats().History(ctx, from, to)
+	if err != nil {
+		return models.SimulationResult{}, err
+	}
+
+	sim := &simulation{
+		ctx:       ctx,
+		teams:     s.store.Teams(),
+		strategy:  req.Strategy,
+		count:     req.ReviewerCount,
+		rng:       rand.New(rand.NewPCG(req.Seed, req.Seed)), //nolint:gosec // reproducible simulation, not security sensitive
+		tiers:     map[string][]repository.CandidateTier{},
+		policies:  map[string]models.EffectivePolicy{},
+		eligible:  map[string]bool{},
+		actual:    map[string]int{},
+		simulated: map[string]int{},
+		openLoad:  map[string]int{},
+	}
+	for _, pr := range prs {
+		if err := sim.replay(pr); err != nil {
+			return models.SimulationResult{}, err
+		}
+	}
+	return sim.result(from, to, len(prs)), nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"sort"
+	"time"
+)
+
+// AssignmentStats returns the per-user and per-team assignment counts,
+// served from the stats cache when possible.
+func (s *Service) AssignmentStats(ctx context.Context, filter repository.AssignmentStatsFilter) (models.AssignmentStatsResponse, error) {
+	key := statsCacheKey(filter)
+	resp, generation, ok := s.statsCache.get(key, time.Now())
+	if ok {
+		resp.Cached = true
+		return resp, nil
+	}
+
+	generatedAt := time.Now()
+	stats := []models.UserAssignmentStats{}
+	err := s.store.Stats().Assignments(ctx, filter, func(u models.UserAssignmentStats) error {
+		stats = append(stats, u)
+		return nil
+	})
+	if err != nil {
+		return resp, fmt.Errorf("failed to fetch assignment stats: %w", err)
+	}
+
+	resp = models.AssignmentStatsResponse{
+		Users:       stats,
+		Teams:       teamTotals(stats),
+		GeneratedAt: generatedAt,
+	}
+	s.statsCache.put(key, generation, resp)
+	return resp, nil
+}
+
+// EachAssignmentStat calls fn for every per-user row straight from the store,
+// bypassing the stats cache. It is meant for exports.
+func (s *Service) EachAssignmentStat(ctx context.Context, filter repository.AssignmentStatsFilter,
+	fn func(models.UserAssignmentStats) error) error {
+	return s.store.Stats().Assignments(ctx, filter, fn)
+}
+
+func teamTotals(stats []models.UserAssignmentStats) []models.TeamAssignmentTotals {
+	byTeam := map[string]*models.TeamAssignmentTotals{}
+	for _, s := range stats {
+		t, ok := byTeam[s.TeamName]
+		if !ok {
+			t = &models.TeamAssignmentTotals{TeamName: s.TeamName}
+			byTeam[s.TeamName] = t
+		}
+		t.MemberCount++
+		t.TotalAssignedPRCount += s.AssignedPRCount
+	}
+
+	totals := make([]models.TeamAssignmentTotals, 0, len(byTeam))
+	for _, t := range byTeam {
+		t.AvgAssignedPRCount = float64(t.TotalAssignedPRCount) / float64(t.MemberCount)
+		totals = append(totals, *t)
+	}
+	sort.Slice(totals, func(i, j int) bool { return totals[i].TeamName < totals[j].TeamName })
+	return totals
+}
+
+// TeamAssignmentStatsTree returns the assignment counts of the team and its
+// subteams, rolled up the hierarchy.
+func (s *Service) TeamAssignmentStatsTree(ctx context.Context, name string) (models.TeamAssignmentStats, error) {
+	forest, err := loadTeamForest(ctx, s.store.Teams())
+	if err != nil {
+		return models.TeamAssignmentStats{}, fmt.Errorf("failed to load teams: %w", err)
+	}
+	if _, ok := forest.teams[name]; !ok {
+		return models.TeamAssignmentStats{}, errTeamNotFound
+	}
+
+	own, err := s.store.Stats().TeamAssignments(ctx, forest.subtree(name))
+	if err != nil {
+		return models.TeamAssignmentStats{}, fmt.Errorf("failed to fetch team assignment stats: %w", err)
+	}
+	return forest.rollUpStats(name, own, map[string]bool{}), nil
+}
+
+func (s *Service) MergeTimes(ctx context.Context, from, to *time.Time) (models.MergeTimeStats, error) {
+	return s.store.Stats().MergeTimes(ctx, from, to)
+}
+
+func (s *Service) ReviewLatency(ctx context.Context, from, to *time.Time) ([]models.ReviewLatencyStats, error) {
+	return s.store.Stats().ReviewLatency(ctx, from, to)
+}
+
+func (s *Service) WeeklyTrends(ctx context.Context, from, to *time.Time) ([]models.WeeklyTrend, error) {
+	return s.store.Stats().WeeklyTrends(ctx, from, to)
+}
+
+// OpenReviews summarizes the open PRs and the review load they put on teams.
+func (s *Service) OpenReviews(ctx context.Context) (repository.OpenReviewSummary, error) {
+	return s.store.Stats().OpenReviews(ctx)
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"fmt"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"sync"
+	"time"
+)
+
+// maxStatsCacheEntries bounds the number of distinct filters kept at once.
+const maxStatsCacheEntries = 256
+
+// statsCache keeps computed /stats/assignments responses per filter. Writes
+// that change users or PR assignments call invalidateStats, which bumps the
+// generation and drops every entry; the TTL only bounds staleness caused by
+// writes made outside this process.
+type statsCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	generation uint64
+	entries    map[string]statsCacheEntry
+}
+
+type statsCacheEntry struct {
+	generation uint64
+	response   models.AssignmentStatsResponse
+}
+
+func newStatsCache(ttl time.Duration) *statsCache {
+	return &statsCache{ttl: ttl, entries: map[string]statsCacheEntry{}}
+}
+
+func statsCacheKey(f repository.AssignmentStatsFilter) string {
+	format := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("%s|%s|%s|%s|%t", f.Team, f.Status, format(f.From), format(f.To), f.ActiveOnly)
+}
+
+// get returns a cached response that is neither invalidated nor expired,
+// together with the current generation to pass to put after a miss.
+func (c *statsCache) get(key string, now time.Time) (models.AssignmentStatsResponse, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || e.generation != c.generation || now.Sub(e.response.GeneratedAt) >= c.ttl {
+		return models.AssignmentStatsResponse{}, c.generation, false
+	}
+	return e.response, c.generation, true
+}
+
+// put stores a response computed at the given generation. Responses computed
+// before a concurrent invalidation are discarded.
+func (c *statsCache) put(key string, generation uint64, resp models.AssignmentStatsResponse) {
+	if c.ttl == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	if len(c.entries) >= maxStatsCacheEntries {
+		clear(c.entries)
+	}
+	c.entries[key] = statsCacheEntry{generation: generation, response: resp}
+}
+
+func (c *statsCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	clear(c.entries)
+}
+
+// invalidateStats must be called after every committed write that changes
+// team membership, user activity or PR assignments.
+func (s *Service) invalidateStats() {
+	s.statsCache.invalidate()
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"reviewer-service/app/models"
+	"testing"
+	"time"
+)
+
+func TestStatsCacheServesUntilInvalidated(t *testing.T) {
+	c := newStatsCache(time.Minute)
+	now := time.Now()
+
+	_, gen, ok := c.get("k", now)
+	if ok {
+		t.Fatal("expected miss on empty cache")
+	}
+	c.put("k", gen, models.AssignmentStatsResponse{GeneratedAt: now})
+
+	if _, _, ok := c.get("k", now.Add(time.Second)); !ok {
+		t.Fatal("expected hit after put")
+	}
+
+	c.invalidate()
+	if _, _, ok := c.get("k", now.Add(time.Second)); ok {
+		t.Fatal("expected miss after invalidation")
+	}
+}
+
+func TestStatsCacheDropsStalePut(t *testing.T) {
+	c := newStatsCache(time.Minute)
+	now := time.Now()
+
+	_, gen, _ := c.get("k", now)
+	c.invalidate() // a write lands while the stats are being computed
+	c.put("k", gen, models.AssignmentStatsResponse{GeneratedAt: now})
+
+	if _, _, ok := c.get("k", now); ok {
+		t.Fatal("expected response computed before invalidation to be discarded")
+	}
+}
+
+func TestStatsCacheExpires(t *testing.T) {
+	c := newStatsCache(time.Second)
+	now := time.Now()
+
+	_, gen, _ := c.get("k", now)
+	c.put("k", gen, models.AssignmentStatsResponse{GeneratedAt: now})
+
+	if _, _, ok := c.get("k", now.Add(2*time.Second)); ok {
+		t.Fatal("expected expired entry to miss")
+	}
+}
+
+func TestStatsCacheDisabled(t *testing.T) {
+	c := newStatsCache(0)
+	now := time.Now()
+
+	_, gen, _ := c.get("k", now)
+	c.put("k", gen, models.AssignmentStatsResponse{GeneratedAt: now})
+
+	if _, _, ok := c.get("k", now); ok {
+		t.Fatal("expected zero TTL to disable caching")
+	}
+}
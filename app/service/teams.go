@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+)
+
+var errTeamNotFound = newError(CodeNotFound, "team not found")
+
+// CreateTeam creates the team and upserts its members, who move over from
+// whatever team they belonged to, in one transaction. Missing kind and member
+// seniority default to team and middle.
+func (s *Service) CreateTeam(ctx context.Context, team models.Team) (_ models.Team, err error) {
+	defer s.auditFailure(ctx, ActionTeamCreate, team.TeamName, &team, &err)
+
+	if team.Kind == "" {
+		team.Kind = "team"
+	}
+	if err := validateTeamSettings(team.Kind, team.Policy); err != nil {
+		return team, err
+	}
+	for _, member := range team.Members {
+		if member.Seniority != "" && !validSeniority(member.Seniority) {
+			return team, errInvalidSeniority
+		}
+	}
+
+	fallbacks, err := normalizeFallbackTeams(team.TeamName, team.FallbackTeams)
+	if err != nil {
+		return team, err
+	}
+	team.FallbackTeams = fallbacks
+
+	for i := range team.Members {
+		if team.Members[i].Seniority == "" {
+			team.Members[i].Seniority = "middle"
+		}
+	}
+
+	err = s.store.InTx(ctx, func(tx repository.Store) error {
+		if err := checkReferencedTeams(ctx, tx.Teams(), team.ParentTeam, fallbacks); err != nil {
+			return err
+		}
+		err := tx.Teams().Create(ctx, team)
+		if errors.Is(err, repository.ErrExists) {
+			return newError(CodeTeamExists, "team_name already exists")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create team: %w", err)
+		}
+		for _, member := range team.Members {
+			if err := tx.Users().Upsert(ctx, team.TeamName, member); err != nil {
+				return fmt.Errorf("failed to save team member %s: %w", member.UserID, err)
+			}
+		}
+		return recordAudit(ctx, tx, ActionTeamCreate, team.TeamName, team)
+	})
+	if err != nil {
+		return team, err
+	}
+	team.Version = 1
+	s.invalidateStats()
+	return team, nil
+}
+
+func (s *Service) GetTeam(ctx context.Context, name string) (models.Team, error) {
+	team, err := s.store.Teams().Get(ctx, name)
+	if errors.Is(err, repository.ErrNotFound) {
+		return team, errTeamNotFound
+	}
+	return team, err
+}
+
+// UpdateTeam applies the fields set in req if the team matches cond and
+// returns the updated team.
+func (s *Service) UpdateTeam(ctx context.Context, req models.UpdateTeamRequest, cond Precondition) (_ models.Team, err error) {
+	defer s.auditFailure(ctx, ActionTeamUpdate, req.TeamName, &req, &err)
+
+	if err := checkTeamAccess(ctx, req.TeamName); err != nil {
+		return models.Team{}, err
+	}
+	if err := checkHierarchyAccess(ctx, req); err != nil {
+		return models.Team{}, err
+	}
+	var team models.Team
+	err = s.store.InTx(ctx, func(tx repository.Store) error {
+		if err := applyTeamUpdate(ctx, tx.Teams(), &req, cond); err != nil {
+			return err
+		}
+		var err error
+		if team, err = tx.Teams().Get(ctx, req.TeamName); err != nil {
+			return fmt.Errorf("failed to load team %s: %w", req.TeamName, err)
+		}
+		return recordAudit(ctx, tx, ActionTeamUpdate, req.TeamName, req)
+	})
+	if err != nil {
+		return models.Team{}, err
+	}
+	return team, nil
+}
+
+// applyTeamUpdate validates req against the locked team row and saves it.
+// On success req.Kind and req.FallbackTeams hold the values written.
+func applyTeamUpdate(ctx context.Context, teams repository.TeamRepo, req *models.UpdateTeamRequest, cond Precondition) error {
+	current, err := teams.Lock(ctx, req.TeamName)
+	if errors.Is(err, repository.ErrNotFound) {
+		return errTeamNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := cond.check(current.Version); err != nil {
+		return err
+	}
+
+	kind := current.Kind
+	if req.Kind != nil {
+		kind = *req.Kind
+	}
+	if err := validateTeamSettings(kind, req.Policy); err != nil {
+		return err
+	}
+	req.Kind = &kind
+
+	var parent string
+	if req.ParentTeam != nil {
+		parent = *req.ParentTeam
+	}
+	var fallbacks []string
+	if req.FallbackTeams != nil {
+		if fallbacks, err = normalizeFallbackTeams(req.TeamName, *req.FallbackTeams); err != nil {
+			return err
+		}
+		req.FallbackTeams = &fallbacks
+	}
+	if err := checkReferencedTeams(ctx, teams, parent, fallbacks); err != nil {
+		return err
+	}
+
+	if parent != "" {
+		if err := teams.LockHierarchy(ctx); err != nil {
+			return err
+		}
+		chain, err := teams.Chain(ctx, parent)
+		if err != nil {
+			return err
+		}
+		// Chain stops at MaxHierarchyDepth, so a longer chain could hide
+		// the team being updated.
+		if len(chain) > repository.MaxHierarchyDepth {
+			return invalid(fmt.Sprintf("parent_team would nest the team deeper than %d levels", repository.MaxHierarchyDepth))
+		}
+		for _, t := range chain {
+			if t.Name == req.TeamName {
+				return invalid("parent_team would create a cycle")
+			}
+		}
+	}
+
+	return teams.Update(ctx, *req)
+}
+
+func validateTeamSettings(kind string, policy *models.TeamPolicy) error {
+	if !validTeamKind(kind) {
+		return invalid("kind must be one of department, team, squad")
+	}
+	if policy == nil {
+		return nil
+	}
+	if policy.ReviewerCount != nil && *policy.ReviewerCount < 0 {
+		return invalid("reviewer_count must not be negative")
+	}
+	if policy.ReviewSLAHours != nil && *policy.ReviewSLAHours <= 0 {
+		return invalid("review_sla_hours must be positive")
+	}
+	return nil
+}
+
+// checkReferencedTeams fails with NOT_FOUND when the parent or one of the
+// fallback teams does not exist.
+func checkReferencedTeams(ctx context.Context, teams repository.TeamRepo, parent string, fallbacks []string) error {
+	if parent != "" {
+		missing, err := teams.FirstMissing(ctx, []string{parent})
+		if err != nil {
+			return fmt.Errorf("failed to check parent team: %w", err)
+		}
+		if missing != "" {
+			return newError(CodeNotFound, "parent team not found")
+		}
+	}
+
+	missing, err := teams.FirstMissing(ctx, fallbacks)
+	if err != nil {
+		return fmt.Errorf("failed to check fallback teams: %w", err)
+	}
+	if missing != "" {
+		return newError(CodeNotFound, "fallback team not found")
+	}
+	return nil
+}
+
+func normalizeFallbackTeams(teamName string, fallbacks []string) ([]string, error) {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, name := range fallbacks {
+		if name == teamName {
+			return nil, invalid("team cannot be its own fallback")
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		result = append(result, name)
+	}
+	return result, nil
+}
+
+// TeamTree returns the team with its members and all subteams, each with its
+// effective policy.
+func (s *Service) TeamTree(ctx context.Context, name string) (models.TeamNode, error) {
+	forest, err := loadTeamForest(ctx, s.store.Teams())
+	if err != nil {
+		return models.TeamNode{}, err
+	}
+	if _, ok := forest.teams[name]; !ok {
+		return models.TeamNode{}, errTeamNotFound
+	}
+
+	members, err := s.store.Users().MembersOf(ctx, forest.subtree(name))
+	if err != nil {
+		return models.TeamNode{}, fmt.Errorf("failed to load members: %w", err)
+	}
+	return forest.buildNode(name, members, map[string]bool{}), nil
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reviewer-service/app/auth"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"time"
+)
+
+var errInvalidToken = newError(CodeUnauthorized, "invalid or revoked API token")
+
+// IssueToken creates a token and returns it together with its secret, which
+// is not stored and cannot be shown again. The audit entry names the token by
+// its ID and never contains the secret.
+func (s *Service) IssueToken(ctx context.Context, req models.IssueTokenRequest) (_ models.APIToken, _ string, err error) {
+	id, secret, err := auth.NewToken()
+	if err != nil {
+		return models.APIToken{}, "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	payload := map[string]string{"role": req.Role}
+	if req.TeamName != "" {
+		payload["team_name"] = req.TeamName
+	}
+	defer s.auditFailure(ctx, ActionTokenIssue, id, payload, &err)
+
+	role := auth.Role(req.Role)
+	switch {
+	case !role.Valid():
+		return models.APIToken{}, "", invalid("role must be one of admin, team-lead, bot, read-only")
+	case role == auth.RoleTeamLead && req.TeamName == "":
+		return models.APIToken{}, "", invalid("team_name is required for the team-lead role")
+	case role != auth.RoleTeamLead && req.TeamName != "":
+		return models.APIToken{}, "", invalid("team_name is only allowed for the team-lead role")
+	}
+	if req.TeamName != "" {
+		if missing, err := s.store.Teams().FirstMissing(ctx, []string{req.TeamName}); err != nil {
+			return models.APIToken{}, "", fmt.Errorf("failed to check team: %w", err)
+		} else if missing != "" {
+			return models.APIToken{}, "", errTeamNotFound
+		}
+	}
+
+	token := models.APIToken{
+		TokenID:   id,
+		Name:      req.Name,
+		Role:      req.Role,
+		TeamName:  req.TeamName,
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	err = s.store.InTx(ctx, func(tx repository.Store) error {
+		if err := tx.Tokens().Create(ctx, token, auth.HashToken(secret)); err != nil {
+			return fmt.Errorf("failed to store token: %w", err)
+		}
+		return recordAudit(ctx, tx, ActionTokenIssue, id, payload)
+	})
+	if err != nil {
+		return models.APIToken{}, "", err
+	}
+	return token, secret, nil
+}
+
+// RevokeToken makes the token unusable. Revoking a revoked token is a no-op.
+func (s *Service) RevokeToken(ctx context.Context, tokenID string) (_ models.APIToken, err error) {
+	defer s.auditFailure(ctx, ActionTokenRevoke, tokenID, nil, &err)
+
+	var token models.APIToken
+	err = s.store.InTx(ctx, func(tx repository.Store) error {
+		var err error
+		token, err = tx.Tokens().Revoke(ctx, tokenID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return newError(CodeNotFound, "token not found")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to revoke token: %w", err)
+		}
+		return recordAudit(ctx, tx, ActionTokenRevoke, tokenID, map[string]string{"role": token.Role})
+	})
+	if err != nil {
+		return models.APIToken{}, err
+	}
+	return token, nil
+}
+
+// Authenticate resolves a token secret to the caller it was issued for.
+func (s *Service) Authenticate(ctx context.Context, secret string) (auth.Principal, error) {
+	token, err := s.store.Tokens().GetByHash(ctx, auth.HashToken(secret))
+	if errors.Is(err, repository.ErrNotFound) {
+		return auth.Principal{}, errInvalidToken
+	}
+	if err != nil {
+		return auth.Principal{}, fmt.Errorf("failed to look up token: %w", err)
+	}
+	if token.RevokedAt != nil {
+		return auth.Principal{}, errInvalidToken
+	}
+	return auth.Principal{TokenID: token.TokenID, Name: token.Name, Role: auth.Role(token.Role), Team: token.TeamName}, nil
+}
+
+// checkTeamAccess fails with FORBIDDEN when the caller in ctx may not change
+// team. Calls made outside an authenticated request are not limited.
+func checkTeamAccess(ctx context.Context, team string) error {
+	if p, ok := auth.FromContext(ctx); ok && !p.CanChangeTeam(team) {
+		return newError(CodeForbidden, fmt.Sprintf("token is limited to team %s", p.Team))
+	}
+	return nil
+}
+
+// checkHierarchyAccess fails with FORBIDDEN when a team lead in ctx tries to
+// change the parent or fallback teams, which decide whose members review the
+// team's PRs.
+func checkHierarchyAccess(ctx context.Context, req models.UpdateTeamRequest) error {
+	p, ok := auth.FromContext(ctx)
+	if !ok || p.Role != auth.RoleTeamLead || (req.ParentTeam == nil && req.FallbackTeams == nil) {
+		return nil
+	}
+	return newError(CodeForbidden, "only admins may change parent_team and fallback_teams")
+}
+
+// checkUserAccess applies checkTeamAccess to the teams of userIDs. Unknown
+// users are skipped and left to the operation to report.
+func checkUserAccess(ctx context.Context, users repository.UserRepo, userIDs ...string) error {
+	if p, ok := auth.FromContext(ctx); !ok || p.Role != auth.RoleTeamLead {
+		return nil
+	}
+	for _, id := range userIDs {
+		user, err := users.Get(ctx, id)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load user %s: %w", id, err)
+		}
+		if err := checkTeamAccess(ctx, user.TeamName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
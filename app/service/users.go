@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reviewer-service/app/models"
+	"reviewer-service/app/repository"
+	"strings"
+)
+
+var errUserNotFound = newError(CodeNotFound, "user not found")
+
+func (s *Service) SetUserActive(ctx context.Context, userID string, active bool) (_ models.User, err error) {
+	payload := map[string]bool{"is_active": active}
+	defer s.auditFailure(ctx, ActionUserSetActive, userID, payload, &err)
+
+	var user models.User
+	err = s.store.InTx(ctx, func(tx repository.Store) error {
+		if err := checkUserAccess(ctx, tx.Users(), userID); err != nil {
+			return err
+		}
+		err := tx.Users().SetActive(ctx, userID, active)
+		if errors.Is(err, repository.ErrNotFound) {
+			return errUserNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to set active flag for %s: %w", userID, err)
+		}
+		if user, err = loadUser(ctx, tx.Users(), userID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, ActionUserSetActive, userID, payload)
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+	s.invalidateStats()
+	return user, nil
+}
+
+func (s *Service) SetUserSeniority(ctx context.Context, userID, seniority string) (models.User, error) {
+	if !validSeniority(seniority) {
+		return models.User{}, errInvalidSeniority
+	}
+
+	err := s.store.InTx(ctx, func(tx repository.Store) error {
+		if err := checkUserAccess(ctx, tx.Users(), userID); err != nil {
+			return err
+		}
+		err := tx.Users().SetSeniority(ctx, userID, seniority)
+		if errors.Is(err, repository.ErrNotFound) {
+			return errUserNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to set seniority for %s: %w", userID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+	return loadUser(ctx, s.store.Users(), userID)
+}
+
+func loadUser(ctx context.Context, users repository.UserRepo, userID string) (models.User, error) {
+	user, err := users.Get(ctx, userID)
+	if err != nil {
+		return user, fmt.Errorf("failed to load user %s: %w", userID, err)
+	}
+	return user, nil
+}
+
+// UserReviews lists the PRs the user is assigned to review.
+func (s *Service) UserReviews(ctx context.Context, userID string) ([]models.PullRequestShort, error) {
+	prs, err := s.store.PullRequests().ListByReviewer(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load PRs for %s: %w", userID, err)
+	}
+	return prs, nil
+}
+
+// DeactivateUsers deactivates userIDs and hands each of their open PRs to a
+// random active teammate of the author, all in one transaction. A PR that
+// cannot get a new reviewer fails the whole operation with BAD_REQUEST.
+func (s *Service) DeactivateUsers(ctx context.Context, userIDs []string) (_ models.DeactivationResponse, err error) {
+	target, payload := strings.Join(userIDs, ","), map[string][]string{"user_ids": userIDs}
+	defer s.auditFailure(ctx, ActionUsersDeactivate, target, payload, &err)
+
+	var resp models.DeactivationResponse
+	err = s.store.InTx(ctx, func(tx repository.Store) error {
+		var err error
+		if resp, err = deactivateUsers(ctx, tx, userIDs); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, ActionUsersDeactivate, target, payload)
+	})
+	if err != nil {
+		return resp, err
+	}
+	s.invalidateStats()
+	return resp, nil
+}
+
+func deactivateUsers(ctx context.Context, tx repository.Store, userIDs []string) (models.DeactivationResponse, error) {
+	resp := models.DeactivationResponse{Status: "completed"}
+
+	if err := checkUserAccess(ctx, tx.Users(), userIDs...); err != nil {
+		return resp, err
+	}
+	deactivated, err := tx.Users().Deactivate(ctx, userIDs)
+	if err != nil {
+		return resp, err
+	}
+	resp.DeactivatedUsers = deactivated
+
+	openPRs, err := tx.PullRequests().OpenByAuthors(ctx, deactivated)
+	if err != nil {
+		return resp, fmt.Errorf("failed to find open PRs: %w", err)
+	}
+
+	for _, pr := range openPRs {
+		newReviewerID, err := AssignNewReviewer(ctx, tx, pr.PullRequestID)
+		var domainErr *Error
+		if errors.As(err, &domainErr) {
+			return resp, &Error{Code: CodeBadRequest, Message: "failed to reassign PR " + pr.PullRequestID, Err: err}
+		}
+		if err != nil {
+			return resp, fmt.Errorf("failed to reassign PR %s: %w", pr.PullRequestID, err)
+		}
+		resp.ReassignmentDetails = append(resp.ReassignmentDetails, models.ReassignmentDetail{
+			PullRequestID: pr.PullRequestID,
+			OldAuthorID:   pr.AuthorID,
+			NewReviewerID: newReviewerID,
+		})
+	}
+	resp.ReassignedPRsCount = len(resp.ReassignmentDetails)
+	return resp, nil
+}
+
+// AssignNewReviewer replaces all reviewers of the PR with one random active
+// member of the author's team. Inside a transaction the PR row stays locked;
+// a PR merged in the meantime fails with PR_MERGED.
+func AssignNewReviewer(ctx context.Context, store repository.Store, prID string) (string, error) {
+	pr, err := store.PullRequests().Lock(ctx, prID)
+	var author models.User
+	if err == nil {
+		author, err = store.Users().Get(ctx, pr.AuthorID)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return "", newError(CodeNotFound, "PR or author not found for PR ID "+prID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch PR author info: %w", err)
+	}
+	if pr.Status == "MERGED" {
+		return "", newError(CodePRMerged, "PR "+prID+" was merged concurrently")
+	}
+
+	newReviewerID, err := store.Users().RandomActiveTeammate(ctx, author.TeamName, pr.AuthorID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return "", newError(CodeNoCandidate, "no suitable active reviewer found in team "+author.TeamName)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to find a new reviewer: %w", err)
+	}
+
+	if _, err := store.PullRequests().SetReviewers(ctx, prID, []string{newReviewerID}); err != nil {
+		return "", err
+	}
+	return newReviewerID, nil
+}
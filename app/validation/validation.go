@@ -0,0 +1,113 @@
+// Package validation collects per-field problems of a request payload so
+// that all of them can be reported at once.
+package validation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxIDLength bounds user, team and pull request identifiers.
+	MaxIDLength = 64
+	// MaxNameLength bounds free-form names such as usernames and PR titles.
+	MaxNameLength = 256
+	// MaxListLength bounds lists of IDs and team members in one request.
+	MaxListLength = 1000
+)
+
+// Errors maps a field path in JSON notation (members[2].user_id) to what is
+// wrong with it. Only the first problem of a field is kept.
+type Errors map[string]string
+
+func (e Errors) Error() string {
+	fields := make([]string, 0, len(e))
+	for f := range e {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f + ": " + e[f]
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Err returns e as an error, or nil when no field failed.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Add records message for field unless the field already failed.
+func (e Errors) Add(field, message string) {
+	if _, ok := e[field]; !ok {
+		e[field] = message
+	}
+}
+
+// Check records message for field when ok is false.
+func (e Errors) Check(ok bool, field, message string) {
+	if !ok {
+		e.Add(field, message)
+	}
+}
+
+// ID requires v to be a non-empty identifier made of ASCII letters, digits,
+// dots, dashes and underscores.
+func (e Errors) ID(field, v string) {
+	if v == "" {
+		e.Add(field, "is required")
+		return
+	}
+	e.OptionalID(field, v)
+}
+
+// OptionalID checks v like ID but accepts an empty value.
+func (e Errors) OptionalID(field, v string) {
+	if v == "" {
+		return
+	}
+	if len(v) > MaxIDLength {
+		e.Add(field, fmt.Sprintf("must be at most %d characters", MaxIDLength))
+		return
+	}
+	for _, c := range v {
+		if !isIDChar(c) {
+			e.Add(field, "may only contain letters, digits, '.', '-' and '_'")
+			return
+		}
+	}
+}
+
+// IDs checks every element of a list of identifiers.
+func (e Errors) IDs(field string, vs []string) {
+	if len(vs) > MaxListLength {
+		e.Add(field, fmt.Sprintf("must have at most %d elements", MaxListLength))
+		return
+	}
+	for i, v := range vs {
+		e.ID(fmt.Sprintf("%s[%d]", field, i), v)
+	}
+}
+
+// Name requires v to be valid UTF-8 text that is not blank and fits
+// MaxNameLength characters.
+func (e Errors) Name(field, v string) {
+	switch {
+	case strings.TrimSpace(v) == "":
+		e.Add(field, "must not be blank")
+	case !utf8.ValidString(v):
+		e.Add(field, "must be valid UTF-8")
+	case utf8.RuneCountInString(v) > MaxNameLength:
+		e.Add(field, fmt.Sprintf("must be at most %d characters", MaxNameLength))
+	}
+}
+
+func isIDChar(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_'
+}
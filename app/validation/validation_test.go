@@ -0,0 +1,41 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	errs := Errors{}
+	errs.ID("empty", "")
+	errs.ID("long", strings.Repeat("a", MaxIDLength+1))
+	errs.ID("charset", "pr/1")
+	errs.ID("ok", "pr-1_v2.0")
+	errs.OptionalID("optional", "")
+	errs.Name("blank", " \t")
+	errs.Name("name", "Алиса")
+	errs.IDs("list", []string{"u1", ""})
+	errs.Add("empty", "second message is ignored")
+
+	want := map[string]string{
+		"empty":   "is required",
+		"long":    "must be at most 64 characters",
+		"charset": "may only contain letters, digits, '.', '-' and '_'",
+		"blank":   "must not be blank",
+		"list[1]": "is required",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), errs)
+	}
+	for field, msg := range want {
+		if errs[field] != msg {
+			t.Fatalf("%s: expected %q, got %q", field, msg, errs[field])
+		}
+	}
+	if !strings.HasPrefix(errs.Error(), "blank: must not be blank; charset:") {
+		t.Fatalf("expected fields sorted in message, got %q", errs.Error())
+	}
+	if (Errors{}).Err() != nil {
+		t.Fatal("expected nil error without failures")
+	}
+}
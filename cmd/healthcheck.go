@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"reviewer-service/app/config"
+	"time"
+)
+
+// healthcheck probes the readiness endpoint of a locally running instance.
+// The runtime image has no curl, so docker compose runs
+// `reviewer-service healthcheck` instead.
+func healthcheck(args []string) int {
+	cfg, err := config.Load(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "healthcheck: invalid configuration: %v\n", err)
+		return 1
+	}
+	_, port, _ := net.SplitHostPort(cfg.HTTP.Addr)
+
+	client := &http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Get("http://" + net.JoinHostPort("localhost", port) + "/health/ready")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "healthcheck: %v\n", err)
+		return 1
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "healthcheck: readiness returned %s\n", resp.Status)
+		return 1
+	}
+	return 0
+}
@@ -1,55 +1,150 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"reviewer-service/app/config"
 	"reviewer-service/app/db"
 	"reviewer-service/app/handlers"
+	"reviewer-service/app/repository"
+	"reviewer-service/app/repository/postgres"
+	"sync"
+	"syscall"
 	"time"
-
-	"github.com/gorilla/mux"
 )
 
 func main() {
-	if err := db.Init(); err != nil {
-		log.Fatal("Failed to connect to DB:", err)
+	if len(os.Args) > 1 && os.Args[1] == "healthcheck" {
+		os.Exit(healthcheck(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(migrate(os.Args[2:]))
 	}
-	defer db.Close()
-
-	r := mux.NewRouter()
 
-	// Team endpoints
-	teamRouter := r.PathPrefix("/team").Subrouter()
-	teamRouter.HandleFunc("/add", handlers.CreateTeamHandler).Methods("POST")
-	teamRouter.HandleFunc("/get", handlers.GetTeamHandler).Methods("GET")
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	log.Printf("Configuration:\n%s", cfg.Redacted())
+	if !cfg.Auth.Enabled {
+		log.Println("WARNING: authentication is disabled, every endpoint is open")
+	}
 
-	// User endpoints
-	userRouter := r.PathPrefix("/users").Subrouter()
-	userRouter.HandleFunc("/setIsActive", handlers.SetUserActiveHandler).Methods("POST")
-	userRouter.HandleFunc("/getReview", handlers.GetUserPRsHandler).Methods("GET")
-	userRouter.HandleFunc("/deactivate", handlers.ProcessUserDeactivationHandler).Methods("POST")
+	if err := serve(cfg); err != nil {
+		log.Fatalf("Server error: %v", err)
+	}
+}
 
-	// PullRequest endpoints
-	prRouter := r.PathPrefix("/pullRequest").Subrouter()
-	prRouter.HandleFunc("/create", handlers.CreatePRHandler).Methods("POST")
-	prRouter.HandleFunc("/merge", handlers.MergePRHandler).Methods("POST")
-	prRouter.HandleFunc("/reassign", handlers.ReassignPRHandler).Methods("POST")
+// serve runs the API until a shutdown signal arrives or the listener fails.
+// Background workers are stopped and the pool is closed before it returns.
+func serve(cfg config.Config) error {
+	if err := db.Init(cfg.DB); err != nil {
+		return fmt.Errorf("failed to connect to DB: %w", err)
+	}
+	defer db.Close()
 
-	// Stats endpoints
-	r.HandleFunc("/stats/assignments", handlers.GetAssignmentStatsHandler).Methods("GET")
+	store := postgres.New(db.Pool)
+	h := handlers.New(store, cfg)
+	r := h.Router(handlers.DefaultQueryTimeouts(cfg.HTTP))
 
-	log.Println("Server starting on :8080")
 	srv := &http.Server{
-		Addr:         ":8080",
+		Addr:         cfg.HTTP.Addr,
 		Handler:      r,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  120 * time.Second,
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
+		WriteTimeout: cfg.HTTP.WriteTimeout,
+		IdleTimeout:  cfg.HTTP.IdleTimeout,
 	}
 
-	log.Println("Server starting on :8080")
-	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		db.Close()
-		log.Printf("server error: %v", err)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var workers sync.WaitGroup
+	workers.Go(func() { purgeIdempotencyKeys(ctx, store.Idempotency(), cfg.HTTP.IdempotencyTTL) })
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("Server starting on %s", cfg.HTTP.Addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		stop()
+		waitCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+		defer cancel()
+		if err := waitFor(waitCtx, &workers); err != nil {
+			log.Printf("background workers did not stop in time: %v", err)
+		}
+		return err
+	case <-ctx.Done():
+		stop()
+		shutdown(srv, h, &workers, cfg.HTTP)
+		return nil
+	}
+}
+
+// purgeIdempotencyKeys deletes expired Idempotency-Key records every hour
+// until ctx is done. Expired records are already ignored on lookup; this
+// only keeps the table small.
+func purgeIdempotencyKeys(ctx context.Context, keys repository.IdempotencyRepo, ttl time.Duration) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := keys.Purge(ctx, time.Now().Add(-ttl))
+			if err != nil {
+				log.Printf("failed to purge idempotency keys: %v", err)
+			} else if n > 0 {
+				log.Printf("purged %d expired idempotency keys", n)
+			}
+		}
+	}
+}
+
+// shutdown fails readiness, gives load balancers the drain delay to notice,
+// then stops accepting connections and waits up to the shutdown timeout for
+// in-flight requests and background workers before the deferred db.Close
+// runs. The timeout must stay below stop_grace_period in docker-compose.yml.
+func shutdown(srv *http.Server, h *handlers.Handler, workers *sync.WaitGroup, cfg config.HTTPConfig) {
+	drainDelay, timeout := cfg.DrainDelay, cfg.ShutdownTimeout
+
+	log.Printf("Shutdown signal received, draining (delay %s, timeout %s)", drainDelay, timeout)
+	h.SetDraining()
+	time.Sleep(drainDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("graceful shutdown did not finish in time: %v", err)
+		if err := srv.Close(); err != nil {
+			log.Printf("failed to close server: %v", err)
+		}
+	}
+	if err := waitFor(ctx, workers); err != nil {
+		log.Printf("background workers did not stop in time: %v", err)
+		return
+	}
+	log.Println("Server stopped")
+}
+
+// waitFor waits for workers until ctx is done.
+func waitFor(ctx context.Context, workers *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
@@ -15,7 +15,9 @@ export const options = {
 };
 
 export default function () {
-  const res = http.get('http://app:8080/stats/assignments');
+  const res = http.get('http://app:8080/stats/assignments', {
+    headers: { Authorization: `Bearer ${__ENV.API_TOKEN}` },
+  });
   
   check(res, { 'status was 200': (r) => r.status === 200 });
   sleep(0.1);